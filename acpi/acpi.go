@@ -0,0 +1,298 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+// Package acpi implements discovery and parsing of Advanced Configuration and
+// Power Interface (ACPI) tables following the specifications at:
+//
+//	https://uefi.org/specifications
+package acpi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	rsdpSignature = "RSD PTR "
+	// ACPI 1.0 RSDP size
+	rsdpV1Size = 20
+	// ACPI 2.0+ RSDP size
+	rsdpSize = 36
+	// RSDP length sanity limit
+	maxRSDPSize = 4096
+
+	headerSize   = 36
+	maxTableSize = 16 << 20
+)
+
+// FACSSignature represents the Firmware ACPI Control Structure signature.
+const FACSSignature = "FACS"
+
+// Memory represents a function to copy physical memory.
+type Memory func(addr uint, size int) []byte
+
+// RSDP represents the Root System Description Pointer structure.
+type RSDP struct {
+	Signature        [8]byte
+	Checksum         uint8
+	OEMID            [6]byte
+	Revision         uint8
+	RsdtAddress      uint32
+	Length           uint32
+	XsdtAddress      uint64
+	ExtendedChecksum uint8
+	_                [3]byte
+}
+
+// Header represents a System Description Table Header.
+type Header struct {
+	Signature       [4]byte
+	Length          uint32
+	Revision        uint8
+	Checksum        uint8
+	OEMID           [6]byte
+	OEMTableID      [8]byte
+	OEMRevision     uint32
+	CreatorID       [4]byte
+	CreatorRevision uint32
+}
+
+// Table represents a System Description Table.
+type Table struct {
+	Header
+
+	// Address is the table physical address.
+	Address uint64
+	// Data is the raw table, including its header.
+	Data []byte
+}
+
+// Signature returns the table signature.
+func (t *Table) Signature() string {
+	return string(t.Header.Signature[:])
+}
+
+// OEM returns the table OEM and OEM table IDs.
+func (t *Table) OEM() (id string, tableID string) {
+	return strings.TrimRight(string(t.OEMID[:]), " \x00"),
+		strings.TrimRight(string(t.OEMTableID[:]), " \x00")
+}
+
+// Valid returns whether the table checksum is valid.
+func (t *Table) Valid() bool {
+	if t.Signature() == FACSSignature {
+		return true
+	}
+
+	return checksum(t.Data)
+}
+
+// GenericAddress represents a Generic Address Structure (GAS).
+type GenericAddress struct {
+	AddressSpaceID    uint8
+	RegisterBitWidth  uint8
+	RegisterBitOffset uint8
+	AccessSize        uint8
+	Address           uint64
+}
+
+// String returns the generic address string representation.
+func (g *GenericAddress) String() string {
+	space := "SystemMemory"
+
+	switch g.AddressSpaceID {
+	case 0x00:
+	case 0x01:
+		space = "SystemIO"
+	case 0x02:
+		space = "PCI"
+	default:
+		space = fmt.Sprintf("%#x", g.AddressSpaceID)
+	}
+
+	return fmt.Sprintf("%s:%#x", space, g.Address)
+}
+
+func checksum(buf []byte) bool {
+	var sum uint8
+
+	for _, b := range buf {
+		sum += b
+	}
+
+	return sum == 0
+}
+
+// ParseRSDP reads and validates the Root System Description Pointer at the
+// argument address.
+func ParseRSDP(mem Memory, addr uint) (rsdp *RSDP, err error) {
+	buf := mem(addr, rsdpSize)
+	rsdp = &RSDP{}
+
+	if _, err = binary.Decode(buf, binary.LittleEndian, rsdp); err != nil {
+		return nil, err
+	}
+
+	if string(rsdp.Signature[:]) != rsdpSignature {
+		return nil, errors.New("invalid RSDP signature")
+	}
+
+	if !checksum(buf[:rsdpV1Size]) {
+		return nil, errors.New("invalid RSDP checksum")
+	}
+
+	if rsdp.Revision < 2 {
+		return
+	}
+
+	if rsdp.Length < rsdpSize || rsdp.Length > maxRSDPSize {
+		return nil, errors.New("invalid RSDP length")
+	}
+
+	if !checksum(mem(addr, int(rsdp.Length))) {
+		return nil, errors.New("invalid RSDP extended checksum")
+	}
+
+	return
+}
+
+// ReadTable reads the System Description Table at the argument address.
+func ReadTable(mem Memory, addr uint64) (t *Table, err error) {
+	if addr == 0 {
+		return nil, errors.New("invalid table address")
+	}
+
+	t = &Table{
+		Address: addr,
+	}
+
+	if _, err = binary.Decode(mem(uint(addr), headerSize), binary.LittleEndian, &t.Header); err != nil {
+		return nil, err
+	}
+
+	if t.Length < headerSize || t.Length > maxTableSize {
+		return nil, fmt.Errorf("invalid table length at %#x", addr)
+	}
+
+	t.Data = mem(uint(addr), int(t.Length))
+
+	return
+}
+
+// Tables walks the XSDT (or RSDT for ACPI 1.0) referenced by the argument
+// RSDP and returns all System Description Tables, including the root table
+// itself and the DSDT and FACS referenced by the FADT.
+func Tables(mem Memory, rsdp *RSDP) (tables []*Table, err error) {
+	var root *Table
+
+	entrySize := 4
+	addr := uint64(rsdp.RsdtAddress)
+
+	if rsdp.Revision >= 2 && rsdp.XsdtAddress != 0 {
+		entrySize = 8
+		addr = rsdp.XsdtAddress
+	}
+
+	if root, err = ReadTable(mem, addr); err != nil {
+		return
+	}
+
+	tables = append(tables, root)
+	entries := root.Data[headerSize:]
+
+	for i := 0; i+entrySize <= len(entries); i += entrySize {
+		var t *Table
+
+		if entrySize == 8 {
+			addr = binary.LittleEndian.Uint64(entries[i:])
+		} else {
+			addr = uint64(binary.LittleEndian.Uint32(entries[i:]))
+		}
+
+		if t, err = ReadTable(mem, addr); err != nil {
+			return
+		}
+
+		tables = append(tables, t)
+
+		if t.Signature() != FADTSignature {
+			continue
+		}
+
+		fadt, err := ParseFADT(t)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if addr = fadt.DSDTAddress(); addr != 0 {
+			if t, err = ReadTable(mem, addr); err != nil {
+				return nil, err
+			}
+
+			tables = append(tables, t)
+		}
+
+		if addr = fadt.FACSAddress(); addr != 0 {
+			tables = append(tables, readFACS(mem, addr))
+		}
+	}
+
+	return
+}
+
+// readFACS reads the Firmware ACPI Control Structure, which unlike other
+// tables has no standard header nor checksum.
+func readFACS(mem Memory, addr uint64) (t *Table) {
+	t = &Table{
+		Address: addr,
+	}
+
+	copy(t.Header.Signature[:], mem(uint(addr), len(t.Header.Signature)))
+	length := binary.LittleEndian.Uint32(mem(uint(addr)+4, 4))
+
+	if length < 8 || length > maxTableSize {
+		length = 64
+	}
+
+	t.Length = length
+	t.Data = mem(uint(addr), int(length))
+
+	return
+}
+
+// Find returns all tables matching the argument signature.
+func Find(tables []*Table, sig string) (res []*Table) {
+	for _, t := range tables {
+		if t.Signature() == sig {
+			res = append(res, t)
+		}
+	}
+
+	return
+}
+
+// decode unmarshals the table fields following its header, tables shorter
+// than the argument structure (e.g. earlier revisions) are zero padded.
+func decode(t *Table, sig string, data any) (err error) {
+	if t.Signature() != sig {
+		return fmt.Errorf("invalid %s signature", sig)
+	}
+
+	if len(t.Data) < headerSize {
+		return fmt.Errorf("invalid %s length", sig)
+	}
+
+	buf := make([]byte, binary.Size(data))
+	copy(buf, t.Data[headerSize:])
+
+	if _, err = binary.Decode(buf, binary.LittleEndian, data); err != nil {
+		return fmt.Errorf("invalid %s table, %v", sig, err)
+	}
+
+	return
+}
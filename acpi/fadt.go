@@ -0,0 +1,132 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package acpi
+
+// FADTSignature represents the Fixed ACPI Description Table signature.
+const FADTSignature = "FACP"
+
+// FADT Fixed Feature Flags
+const (
+	FADT_WBINVD            = 1 << 0
+	FADT_PROC_C1           = 1 << 2
+	FADT_PWR_BUTTON        = 1 << 4
+	FADT_SLP_BUTTON        = 1 << 5
+	FADT_RTC_S4            = 1 << 7
+	FADT_TMR_VAL_EXT       = 1 << 8
+	FADT_RESET_REG_SUP     = 1 << 10
+	FADT_HEADLESS          = 1 << 12
+	FADT_HW_REDUCED_ACPI   = 1 << 20
+	FADT_LOW_POWER_S0_IDLE = 1 << 21
+)
+
+// IA-PC Boot Architecture Flags
+const (
+	IAPC_LEGACY_DEVICES       = 1 << 0
+	IAPC_8042                 = 1 << 1
+	IAPC_VGA_NOT_PRESENT      = 1 << 2
+	IAPC_MSI_NOT_SUPPORTED    = 1 << 3
+	IAPC_PCIE_ASPM_CONTROLS   = 1 << 4
+	IAPC_CMOS_RTC_NOT_PRESENT = 1 << 5
+)
+
+var pmProfiles = []string{
+	"Unspecified",
+	"Desktop",
+	"Mobile",
+	"Workstation",
+	"Enterprise Server",
+	"SOHO Server",
+	"Appliance PC",
+	"Performance Server",
+	"Tablet",
+}
+
+// FADT represents the Fixed ACPI Description Table fields, following its
+// header, up to the ACPI 2.0 X_DSDT field.
+type FADT struct {
+	FirmwareControl          uint32
+	DSDT                     uint32
+	_                        uint8
+	PreferredPMProfile       uint8
+	SCIInterrupt             uint16
+	SMICommand               uint32
+	ACPIEnable               uint8
+	ACPIDisable              uint8
+	S4BIOSRequest            uint8
+	PStateControl            uint8
+	PM1aEventBlock           uint32
+	PM1bEventBlock           uint32
+	PM1aControlBlock         uint32
+	PM1bControlBlock         uint32
+	PM2ControlBlock          uint32
+	PMTimerBlock             uint32
+	GPE0Block                uint32
+	GPE1Block                uint32
+	PM1EventLength           uint8
+	PM1ControlLength         uint8
+	PM2ControlLength         uint8
+	PMTimerLength            uint8
+	GPE0BlockLength          uint8
+	GPE1BlockLength          uint8
+	GPE1Base                 uint8
+	CStateControl            uint8
+	WorstC2Latency           uint16
+	WorstC3Latency           uint16
+	FlushSize                uint16
+	FlushStride              uint16
+	DutyOffset               uint8
+	DutyWidth                uint8
+	DayAlarm                 uint8
+	MonthAlarm               uint8
+	Century                  uint8
+	BootArchitectureFlags    uint16
+	_                        uint8
+	Flags                    uint32
+	ResetRegister            GenericAddress
+	ResetValue               uint8
+	ARMBootArchitectureFlags uint16
+	MinorVersion             uint8
+	XFirmwareControl         uint64
+	XDSDT                    uint64
+}
+
+// ParseFADT parses a Fixed ACPI Description Table.
+func ParseFADT(t *Table) (fadt *FADT, err error) {
+	fadt = &FADT{}
+
+	if err = decode(t, FADTSignature, fadt); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// DSDTAddress returns the Differentiated System Description Table address.
+func (f *FADT) DSDTAddress() uint64 {
+	if f.XDSDT != 0 {
+		return f.XDSDT
+	}
+
+	return uint64(f.DSDT)
+}
+
+// FACSAddress returns the Firmware ACPI Control Structure address.
+func (f *FADT) FACSAddress() uint64 {
+	if f.XFirmwareControl != 0 {
+		return f.XFirmwareControl
+	}
+
+	return uint64(f.FirmwareControl)
+}
+
+// Profile returns the preferred power management profile name.
+func (f *FADT) Profile() string {
+	if int(f.PreferredPMProfile) < len(pmProfiles) {
+		return pmProfiles[f.PreferredPMProfile]
+	}
+
+	return "Reserved"
+}
@@ -0,0 +1,45 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package acpi
+
+// HPETSignature represents the IA-PC High Precision Event Timer Table
+// signature.
+const HPETSignature = "HPET"
+
+// HPET represents the IA-PC High Precision Event Timer Table.
+type HPET struct {
+	EventTimerBlockID uint32
+	BaseAddress       GenericAddress
+	Number            uint8
+	MinimumTick       uint16
+	PageProtection    uint8
+}
+
+// ParseHPET parses an IA-PC High Precision Event Timer Table.
+func ParseHPET(t *Table) (hpet *HPET, err error) {
+	hpet = &HPET{}
+
+	if err = decode(t, HPETSignature, hpet); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Comparators returns the number of comparators in the first timer block.
+func (h *HPET) Comparators() int {
+	return int((h.EventTimerBlockID>>8)&0x1f) + 1
+}
+
+// Counter64 returns whether the main counter is 64-bit capable.
+func (h *HPET) Counter64() bool {
+	return h.EventTimerBlockID&(1<<13) != 0
+}
+
+// VendorID returns the PCI vendor ID of the first timer block.
+func (h *HPET) VendorID() uint16 {
+	return uint16(h.EventTimerBlockID >> 16)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package acpi
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// MADTSignature represents the Multiple APIC Description Table signature.
+const MADTSignature = "APIC"
+
+// MADT Interrupt Controller Structure Types
+const (
+	MADT_LOCAL_APIC          = 0x00
+	MADT_IO_APIC             = 0x01
+	MADT_INTERRUPT_OVERRIDE  = 0x02
+	MADT_LOCAL_APIC_NMI      = 0x04
+	MADT_LOCAL_APIC_OVERRIDE = 0x05
+	MADT_LOCAL_X2APIC        = 0x09
+)
+
+// Local APIC Flags
+const (
+	LAPIC_ENABLED        = 1 << 0
+	LAPIC_ONLINE_CAPABLE = 1 << 1
+)
+
+// LocalAPIC represents a Processor Local APIC or Local x2APIC structure.
+type LocalAPIC struct {
+	// ProcessorUID is the ACPI processor UID.
+	ProcessorUID uint32
+	// ID is the processor local (x2)APIC ID.
+	ID uint32
+	// Flags are the local APIC flags.
+	Flags uint32
+	// X2APIC is set for Local x2APIC structures.
+	X2APIC bool
+}
+
+// Enabled returns whether the processor is enabled.
+func (l *LocalAPIC) Enabled() bool {
+	return l.Flags&LAPIC_ENABLED != 0
+}
+
+// OnlineCapable returns whether the processor can be enabled at runtime.
+func (l *LocalAPIC) OnlineCapable() bool {
+	return l.Flags&LAPIC_ONLINE_CAPABLE != 0
+}
+
+// IOAPIC represents an I/O APIC structure.
+type IOAPIC struct {
+	ID      uint8
+	_       uint8
+	Address uint32
+	GSIBase uint32
+}
+
+// InterruptOverride represents an Interrupt Source Override structure.
+type InterruptOverride struct {
+	Bus    uint8
+	Source uint8
+	GSI    uint32
+	Flags  uint16
+}
+
+// MADT represents the Multiple APIC Description Table.
+type MADT struct {
+	// LocalAPICAddress is the local APIC physical address.
+	LocalAPICAddress uint64
+	// Flags are the multiple APIC flags.
+	Flags uint32
+
+	// LocalAPICs are the processor local APIC structures.
+	LocalAPICs []*LocalAPIC
+	// IOAPICs are the I/O APIC structures.
+	IOAPICs []*IOAPIC
+	// Overrides are the interrupt source override structures.
+	Overrides []*InterruptOverride
+}
+
+// ParseMADT parses a Multiple APIC Description Table.
+func ParseMADT(t *Table) (madt *MADT, err error) {
+	var hdr struct {
+		LocalAPICAddress uint32
+		Flags            uint32
+	}
+
+	if err = decode(t, MADTSignature, &hdr); err != nil {
+		return
+	}
+
+	madt = &MADT{
+		LocalAPICAddress: uint64(hdr.LocalAPICAddress),
+		Flags:            hdr.Flags,
+	}
+
+	for off := headerSize + 8; off+2 <= len(t.Data); {
+		typ := t.Data[off]
+		n := int(t.Data[off+1])
+
+		if n < 2 || off+n > len(t.Data) {
+			return nil, errors.New("invalid MADT structure length")
+		}
+
+		buf := t.Data[off+2 : off+n]
+		off += n
+
+		switch typ {
+		case MADT_LOCAL_APIC:
+			if len(buf) < 6 {
+				break
+			}
+
+			madt.LocalAPICs = append(madt.LocalAPICs, &LocalAPIC{
+				ProcessorUID: uint32(buf[0]),
+				ID:           uint32(buf[1]),
+				Flags:        binary.LittleEndian.Uint32(buf[2:]),
+			})
+		case MADT_LOCAL_X2APIC:
+			if len(buf) < 14 {
+				break
+			}
+
+			madt.LocalAPICs = append(madt.LocalAPICs, &LocalAPIC{
+				ID:           binary.LittleEndian.Uint32(buf[2:]),
+				Flags:        binary.LittleEndian.Uint32(buf[6:]),
+				ProcessorUID: binary.LittleEndian.Uint32(buf[10:]),
+				X2APIC:       true,
+			})
+		case MADT_IO_APIC:
+			ioapic := &IOAPIC{}
+
+			if _, err := binary.Decode(buf, binary.LittleEndian, ioapic); err == nil {
+				madt.IOAPICs = append(madt.IOAPICs, ioapic)
+			}
+		case MADT_INTERRUPT_OVERRIDE:
+			override := &InterruptOverride{}
+
+			if _, err := binary.Decode(buf, binary.LittleEndian, override); err == nil {
+				madt.Overrides = append(madt.Overrides, override)
+			}
+		case MADT_LOCAL_APIC_OVERRIDE:
+			if len(buf) >= 10 {
+				madt.LocalAPICAddress = binary.LittleEndian.Uint64(buf[2:])
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,48 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package acpi
+
+import (
+	"encoding/binary"
+)
+
+// MCFGSignature represents the PCI Express Memory Mapped Configuration Space
+// Base Address Description Table signature.
+const MCFGSignature = "MCFG"
+
+// MCFGEntry represents an MCFG configuration space base address allocation
+// structure.
+type MCFGEntry struct {
+	BaseAddress uint64
+	Segment     uint16
+	StartBus    uint8
+	EndBus      uint8
+	_           uint32
+}
+
+// ParseMCFG parses a PCI Express Memory Mapped Configuration Space Base
+// Address Description Table.
+func ParseMCFG(t *Table) (entries []*MCFGEntry, err error) {
+	var reserved uint64
+
+	if err = decode(t, MCFGSignature, &reserved); err != nil {
+		return
+	}
+
+	size := binary.Size(&MCFGEntry{})
+
+	for off := headerSize + 8; off+size <= len(t.Data); off += size {
+		e := &MCFGEntry{}
+
+		if _, err = binary.Decode(t.Data[off:], binary.LittleEndian, e); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return
+}
@@ -0,0 +1,372 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+// Package client implements a host-side driver for the go-boot shell over
+// serial devices, pseudo terminals or SSH, for use in automation and
+// regression testing.
+package client
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// DefaultPrompt represents the go-boot shell prompt (see
+// `shell.DefaultPrompt`).
+const DefaultPrompt = "> "
+
+// DefaultTimeout represents the default command timeout.
+const DefaultTimeout = 10 * time.Second
+
+// BannerPrefix represents the go-boot shell banner prefix.
+const BannerPrefix = "go-boot • "
+
+// boot messages logged on successful kernel or EFI image handover
+var booted = regexp.MustCompile(`(go-boot exiting EFI boot services|starting EFI image)`)
+
+// terminal escape sequences
+var escape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// hex.Dump() line offset
+var dumpOffset = regexp.MustCompile(`^[[:xdigit:]]{8}  `)
+
+// ErrTimeout is returned when the shell does not respond within the client
+// timeout.
+var ErrTimeout = errors.New("timeout")
+
+// ASCII control characters
+const (
+	ctrlC = 0x03 // interrupt
+)
+
+// Client represents a go-boot shell connection.
+type Client struct {
+	// Prompt represents the shell prompt, DefaultPrompt is used when
+	// empty.
+	Prompt string
+	// Timeout represents the command timeout, DefaultTimeout is used
+	// when zero.
+	Timeout time.Duration
+	// Banner represents the shell banner, set by Ready() when received.
+	Banner string
+
+	conn io.ReadWriteCloser
+	ch   chan []byte
+	err  error
+	buf  []byte
+}
+
+// New returns a client for an established shell connection.
+func New(conn io.ReadWriteCloser) (c *Client) {
+	c = &Client{
+		conn: conn,
+		ch:   make(chan []byte, 16),
+	}
+
+	go c.read()
+
+	return
+}
+
+// Open returns a client for a serial device or pseudo terminal (e.g. QEMU
+// `-serial pty`), the terminal is set in raw mode while its line settings
+// (e.g. baud rate) are left unchanged.
+func Open(path string) (c *Client, err error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+
+	if err != nil {
+		return
+	}
+
+	if term.IsTerminal(int(f.Fd())) {
+		if _, err = term.MakeRaw(int(f.Fd())); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("could not set raw mode, %v", err)
+		}
+	}
+
+	return New(f), nil
+}
+
+// Close closes the shell connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) read() {
+	for {
+		buf := make([]byte, 4096)
+		n, err := c.conn.Read(buf)
+
+		if n > 0 {
+			c.ch <- buf[:n]
+		}
+
+		if err != nil {
+			c.err = err
+			close(c.ch)
+			return
+		}
+	}
+}
+
+func (c *Client) prompt() string {
+	if len(c.Prompt) == 0 {
+		return DefaultPrompt
+	}
+
+	return c.Prompt
+}
+
+func (c *Client) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTimeout
+	}
+
+	return c.Timeout
+}
+
+// clean strips terminal escape sequences and carriage returns.
+func clean(buf []byte) string {
+	s := escape.ReplaceAllString(string(buf), "")
+	return strings.ReplaceAll(s, "\r", "")
+}
+
+// expect reads until the cleaned received output matches the argument
+// expression, it returns the output preceding the match.
+func (c *Client) expect(re *regexp.Regexp, timeout time.Duration) (out string, m []string, err error) {
+	deadline := time.After(timeout)
+
+	for {
+		s := clean(c.buf)
+
+		if loc := re.FindStringSubmatchIndex(s); loc != nil {
+			m = make([]string, len(loc)/2)
+
+			for i := range m {
+				if loc[i*2] >= 0 {
+					m[i] = s[loc[i*2]:loc[i*2+1]]
+				}
+			}
+
+			c.buf = []byte(s[loc[1]:])
+
+			return s[:loc[0]], m, nil
+		}
+
+		select {
+		case p, ok := <-c.ch:
+			if !ok {
+				return s, nil, c.err
+			}
+
+			c.buf = append(c.buf, p...)
+		case <-deadline:
+			return s, nil, ErrTimeout
+		}
+	}
+}
+
+// expectPrompt reads until the shell prompt is received at the beginning of
+// a line.
+func (c *Client) expectPrompt(timeout time.Duration) (out string, err error) {
+	re := regexp.MustCompile(`(?:^|\n)` + regexp.QuoteMeta(c.prompt()) + `$`)
+	out, _, err = c.expect(re, timeout)
+	return
+}
+
+// Ready waits for the shell prompt, recording the banner when received.
+// When the shell is already running a new prompt is requested.
+func (c *Client) Ready() (err error) {
+	wait := min(time.Second, c.timeout())
+	deadline := time.Now().Add(c.timeout())
+
+	for {
+		var out string
+
+		out, err = c.expectPrompt(wait)
+
+		if _, banner, ok := strings.Cut(out, BannerPrefix); ok {
+			banner, _, _ = strings.Cut(banner, "\n")
+			c.Banner = BannerPrefix + banner
+		}
+
+		if err != ErrTimeout || time.Now().After(deadline) {
+			return
+		}
+
+		// request a new prompt
+		if _, err = c.conn.Write([]byte("\r")); err != nil {
+			return
+		}
+	}
+}
+
+// interrupt sends Ctrl-C and waits for the shell prompt, to resynchronize
+// after a timeout.
+func (c *Client) interrupt() {
+	c.conn.Write([]byte{ctrlC})
+	c.expectPrompt(time.Second)
+	c.buf = nil
+}
+
+// send writes a command and waits for its echo.
+func (c *Client) send(cmd string) (err error) {
+	c.buf = nil
+
+	if strings.ContainsAny(cmd, "\r\n") {
+		return errors.New("invalid command, multiple lines")
+	}
+
+	if _, err = c.conn.Write([]byte(cmd + "\r")); err != nil {
+		return
+	}
+
+	// discard the command line echo, which might wrap
+	var echo strings.Builder
+
+	for _, r := range cmd {
+		echo.WriteString(regexp.QuoteMeta(string(r)) + `\n?`)
+	}
+
+	if _, _, err = c.expect(regexp.MustCompile(echo.String()+`\n`), c.timeout()); err == ErrTimeout {
+		c.interrupt()
+	}
+
+	return
+}
+
+// commandError returns the error reported by the shell, if any, as last
+// output line.
+func commandError(out string) error {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	if msg, ok := strings.CutPrefix(lines[len(lines)-1], "command error, "); ok {
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// Run executes a shell command and returns its output, command errors are
+// returned as error.
+func (c *Client) Run(cmd string) (out string, err error) {
+	if err = c.send(cmd); err != nil {
+		return
+	}
+
+	if out, err = c.expectPrompt(c.timeout()); err == ErrTimeout {
+		c.interrupt()
+		return
+	}
+
+	if err = commandError(out); err != nil {
+		return "", err
+	}
+
+	return
+}
+
+// RunJSON executes a shell command in JSON output mode and decodes its
+// result into the value pointed to by v.
+func (c *Client) RunJSON(cmd string, v any) (err error) {
+	var res struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+
+	out, err := c.Run("json " + cmd)
+
+	if err != nil {
+		return
+	}
+
+	// the result is the last line, preceded by any log output
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	if err = json.Unmarshal([]byte(lines[len(lines)-1]), &res); err != nil {
+		return fmt.Errorf("could not parse result, %v", err)
+	}
+
+	if len(res.Error) > 0 {
+		return errors.New(res.Error)
+	}
+
+	if v == nil || len(res.Result) == 0 {
+		return
+	}
+
+	return json.Unmarshal(res.Result, v)
+}
+
+// ReadFile reads a file from the EFI System Partition.
+func (c *Client) ReadFile(path string) (buf []byte, err error) {
+	out, err := c.Run(fmt.Sprintf("cat %s | hexdump", path))
+
+	if err != nil {
+		return
+	}
+
+	var b bytes.Buffer
+
+	// parse hex.Dump() format: offset, hex bytes, ASCII representation
+	for line := range strings.Lines(out) {
+		// skip any interleaved log output
+		if !dumpOffset.MatchString(line) {
+			continue
+		}
+
+		data, _, _ := strings.Cut(line[10:], "|")
+		data = strings.ReplaceAll(strings.TrimSpace(data), " ", "")
+
+		p, err := hex.DecodeString(data)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not parse output, %v", err)
+		}
+
+		b.Write(p)
+	}
+
+	return b.Bytes(), nil
+}
+
+// Boot boots a UAPI Boot Loader Entry (e.g. `\loader\entries\arch.conf`),
+// or the default one when empty. The shell connection is no longer usable
+// after a successful boot.
+func (c *Client) Boot(entry string) (err error) {
+	if err = c.send(strings.TrimSpace("linux " + entry)); err != nil {
+		return
+	}
+
+	re := regexp.MustCompile(`(?:^|\n)(` + regexp.QuoteMeta(c.prompt()) + `)$|` + booted.String())
+	out, m, err := c.expect(re, c.timeout())
+
+	switch {
+	case err == ErrTimeout:
+		c.interrupt()
+		return
+	case err != nil:
+		return
+	case len(m[1]) > 0:
+		if err = commandError(out); err != nil {
+			return
+		}
+
+		return fmt.Errorf("boot failed, %s", strings.TrimSpace(out))
+	}
+
+	return
+}
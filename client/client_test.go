@@ -0,0 +1,187 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBanner = BannerPrefix + "go1.26 tamago/amd64 (test)"
+
+// testShell returns a client connected to a fake shell which echoes
+// commands, wrapping them at the argument width, and responds with the
+// handler output.
+func testShell(t *testing.T, width int, handler func(cmd string) string) *Client {
+	local, remote := net.Pipe()
+
+	c := New(local)
+	c.Timeout = 5 * time.Second
+
+	t.Cleanup(func() {
+		c.Close()
+		remote.Close()
+	})
+
+	go func() {
+		r := bufio.NewReader(remote)
+
+		if _, err := remote.Write([]byte("\x1b[1;36m" + testBanner + "\x1b[0m\r\n\r\n" + DefaultPrompt)); err != nil {
+			return
+		}
+
+		for {
+			cmd, err := r.ReadString('\r')
+
+			if err != nil {
+				return
+			}
+
+			cmd = strings.TrimSuffix(cmd, "\r")
+
+			var echo strings.Builder
+
+			for len(cmd) > width {
+				echo.WriteString(cmd[:width] + "\r\n")
+				cmd = cmd[width:]
+			}
+
+			echo.WriteString(cmd + "\r\n")
+
+			if _, err = remote.Write([]byte(echo.String())); err != nil {
+				return
+			}
+
+			out := strings.ReplaceAll(handler(strings.ReplaceAll(echo.String(), "\r\n", "")), "\n", "\r\n")
+
+			if _, err = remote.Write([]byte(out + DefaultPrompt)); err != nil {
+				return
+			}
+		}
+	}()
+
+	if err := c.Ready(); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestReady(t *testing.T) {
+	c := testShell(t, 80, func(string) string { return "" })
+
+	if c.Banner != testBanner {
+		t.Errorf("unexpected banner %q", c.Banner)
+	}
+}
+
+func TestRun(t *testing.T) {
+	// the command echo wraps at 8 columns
+	c := testShell(t, 8, func(cmd string) string {
+		return strings.TrimPrefix(cmd, "echo ") + "\n"
+	})
+
+	for _, text := range []string{"hello", "hello world, wrapped", "a > b | c"} {
+		out, err := c.Run("echo " + text)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out != text {
+			t.Errorf("unexpected output %q", out)
+		}
+	}
+}
+
+func TestRunError(t *testing.T) {
+	c := testShell(t, 80, func(string) string {
+		return "partial output\ncommand error, unknown command, type `help`\n"
+	})
+
+	if _, err := c.Run("missing"); err == nil || err.Error() != "unknown command, type `help`" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	for _, test := range []struct {
+		out string
+		err string
+	}{
+		{"", ""},
+		{"output\n", ""},
+		{"command error, invalid\n", "invalid"},
+		{"output\ncommand error, invalid\n\n", "invalid"},
+		{"command error, invalid\noutput\n", ""},
+	} {
+		err := commandError(test.out)
+
+		switch {
+		case len(test.err) == 0 && err != nil:
+			t.Errorf("%q: unexpected error %v", test.out, err)
+		case len(test.err) > 0 && (err == nil || err.Error() != test.err):
+			t.Errorf("%q: expected error %q, got %v", test.out, test.err, err)
+		}
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	var res []struct {
+		Name string `json:"name"`
+		Dir  bool   `json:"dir"`
+	}
+
+	c := testShell(t, 80, func(cmd string) string {
+		switch cmd {
+		case "json ls":
+			return "2026/10/18 12:00:00 log output\n" + `{"result":[{"name":"EFI","dir":true}]}` + "\n"
+		default:
+			return `{"error":"could not read file, file does not exist"}` + "\n"
+		}
+	})
+
+	if err := c.RunJSON("ls", &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 || res[0].Name != "EFI" || !res[0].Dir {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	if err := c.RunJSON(`cat \missing`, nil); err == nil || err.Error() != "could not read file, file does not exist" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	data := []byte("#!gbsh\necho |hello|\n\x00\x01\x02\xff")
+
+	c := testShell(t, 80, func(cmd string) string {
+		if cmd != `cat \startup.gbsh | hexdump` {
+			return "command error, unexpected command\n"
+		}
+
+		// log output interleaved with the dump
+		dump := strings.SplitAfter(hex.Dump(data), "\n")
+		return dump[0] + "2026/10/18 12:00:00 log output\n" + strings.Join(dump[1:], "")
+	})
+
+	buf, err := c.ReadFile(`\startup.gbsh`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf, data) {
+		t.Errorf("unexpected contents %q", buf)
+	}
+}
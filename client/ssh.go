@@ -0,0 +1,73 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshConn represents an SSH session shell connection.
+type sshConn struct {
+	io.Reader
+	io.WriteCloser
+
+	client  *ssh.Client
+	session *ssh.Session
+}
+
+func (s *sshConn) Close() error {
+	s.session.Close()
+	return s.client.Close()
+}
+
+// DialSSH returns a client for the go-boot SSH console (see `net` command
+// `debug` option), the address is in host:port format.
+func DialSSH(addr string) (c *Client, err error) {
+	conf := &ssh.ClientConfig{
+		User: "go-boot",
+		// the debug console is unauthenticated and its host key is
+		// ephemeral
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         DefaultTimeout,
+	}
+
+	client, err := ssh.Dial("tcp", addr, conf)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not connect, %v", err)
+	}
+
+	conn := &sshConn{
+		client: client,
+	}
+
+	defer func() {
+		if err != nil {
+			client.Close()
+		}
+	}()
+
+	if conn.session, err = client.NewSession(); err != nil {
+		return nil, fmt.Errorf("could not open session, %v", err)
+	}
+
+	if conn.Reader, err = conn.session.StdoutPipe(); err != nil {
+		return
+	}
+
+	if conn.WriteCloser, err = conn.session.StdinPipe(); err != nil {
+		return
+	}
+
+	if err = conn.session.Shell(); err != nil {
+		return nil, fmt.Errorf("could not start shell, %v", err)
+	}
+
+	return New(conn), nil
+}
@@ -0,0 +1,239 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/usbarmory/go-boot/acpi"
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "acpi",
+		Spec: []shell.Arg{
+			{Name: "signature", Optional: true, Help: "table signature (e.g. FACP)"},
+		},
+		Help: "list ACPI tables or decode one",
+		Fn:   acpiCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "acpi dump",
+		Spec: []shell.Arg{
+			{Name: "signature", Help: "table signature (e.g. SSDT)"},
+			{Name: "index", Kind: shell.Uint, Optional: true, Help: "table instance"},
+			{Name: "path", Help: "output file"},
+		},
+		Help: "write raw ACPI table to file",
+		Fn:   acpiDumpCmd,
+	})
+}
+
+func readMemory(addr uint, size int) []byte {
+	return memCopy(addr, size, nil)
+}
+
+func acpiTables() (rsdp *acpi.RSDP, tables []*acpi.Table, err error) {
+	var t *uefi.ConfigurationTable
+
+	c := x64.UEFI.SystemTable
+
+	if t, err = c.LocateConfiguration(uefi.EFI_ACPI_20_TABLE_GUID); err != nil {
+		if t, err = c.LocateConfiguration(uefi.ACPI_TABLE_GUID); err != nil {
+			return nil, nil, fmt.Errorf("could not find RSDP, %v", err)
+		}
+	}
+
+	if rsdp, err = acpi.ParseRSDP(readMemory, uint(t.VendorTable)); err != nil {
+		return
+	}
+
+	tables, err = acpi.Tables(readMemory, rsdp)
+
+	return
+}
+
+func acpiCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+
+	rsdp, tables, err := acpiTables()
+
+	if err != nil {
+		return
+	}
+
+	if len(arg[0]) > 0 {
+		found := acpi.Find(tables, arg[0])
+
+		if len(found) == 0 {
+			return "", fmt.Errorf("could not find %s table", arg[0])
+		}
+
+		for _, t := range found {
+			if err = acpiDecode(&buf, t); err != nil {
+				return
+			}
+		}
+
+		return buf.String(), nil
+	}
+
+	fmt.Fprintf(&buf, "RSDP revision %d OEM %s (RSDT %#x XSDT %#x)\n",
+		rsdp.Revision, strings.TrimSpace(string(rsdp.OEMID[:])),
+		rsdp.RsdtAddress, rsdp.XsdtAddress)
+
+	fmt.Fprintf(&buf, "Sig  Address          Length     Rev OEM    Table ID Checksum\n")
+
+	for _, t := range tables {
+		fmt.Fprintf(&buf, "%s %016x %#08x %3d ", t.Signature(), t.Address, t.Length, t.Revision)
+		acpiOEM(&buf, t)
+	}
+
+	return buf.String(), nil
+}
+
+func acpiOEM(buf *bytes.Buffer, t *acpi.Table) {
+	id, tableID := t.OEM()
+	status := "ok"
+
+	if !t.Valid() {
+		status = "INVALID"
+	}
+
+	fmt.Fprintf(buf, "%-6s %-8s %s\n", id, tableID, status)
+}
+
+func acpiDecode(buf *bytes.Buffer, t *acpi.Table) (err error) {
+	fmt.Fprintf(buf, "%s @ %#x length:%d revision:%d ", t.Signature(), t.Address, t.Length, t.Revision)
+	acpiOEM(buf, t)
+
+	switch t.Signature() {
+	case acpi.FADTSignature:
+		var fadt *acpi.FADT
+
+		if fadt, err = acpi.ParseFADT(t); err != nil {
+			return
+		}
+
+		fmt.Fprintf(buf, "  Profile ............: %s\n", fadt.Profile())
+		fmt.Fprintf(buf, "  SCI interrupt ......: %d\n", fadt.SCIInterrupt)
+		fmt.Fprintf(buf, "  SMI command ........: %#x\n", fadt.SMICommand)
+		fmt.Fprintf(buf, "  PM1a event/control .: %#x/%#x\n", fadt.PM1aEventBlock, fadt.PM1aControlBlock)
+		fmt.Fprintf(buf, "  PM timer ...........: %#x (32-bit:%v)\n", fadt.PMTimerBlock, fadt.Flags&acpi.FADT_TMR_VAL_EXT != 0)
+		fmt.Fprintf(buf, "  Century ............: %#x\n", fadt.Century)
+		fmt.Fprintf(buf, "  Boot architecture ..: %#04x (8042:%v VGA:%v)\n", fadt.BootArchitectureFlags,
+			fadt.BootArchitectureFlags&acpi.IAPC_8042 != 0,
+			fadt.BootArchitectureFlags&acpi.IAPC_VGA_NOT_PRESENT == 0)
+		fmt.Fprintf(buf, "  Flags ..............: %#08x (hw-reduced:%v headless:%v)\n", fadt.Flags,
+			fadt.Flags&acpi.FADT_HW_REDUCED_ACPI != 0,
+			fadt.Flags&acpi.FADT_HEADLESS != 0)
+
+		if fadt.Flags&acpi.FADT_RESET_REG_SUP != 0 {
+			fmt.Fprintf(buf, "  Reset register .....: %s value:%#x\n", &fadt.ResetRegister, fadt.ResetValue)
+		}
+
+		fmt.Fprintf(buf, "  FACS ...............: %#x\n", fadt.FACSAddress())
+		fmt.Fprintf(buf, "  DSDT ...............: %#x\n", fadt.DSDTAddress())
+	case acpi.MADTSignature:
+		var madt *acpi.MADT
+
+		if madt, err = acpi.ParseMADT(t); err != nil {
+			return
+		}
+
+		fmt.Fprintf(buf, "  Local APIC .........: %#x\n", madt.LocalAPICAddress)
+		fmt.Fprintf(buf, "  Flags ..............: %#x\n", madt.Flags)
+
+		for _, lapic := range madt.LocalAPICs {
+			kind := "LAPIC  "
+
+			if lapic.X2APIC {
+				kind = "x2APIC "
+			}
+
+			fmt.Fprintf(buf, "  CPU %-3d %s ID:%-4d enabled:%v online-capable:%v\n",
+				lapic.ProcessorUID, kind, lapic.ID, lapic.Enabled(), lapic.OnlineCapable())
+		}
+
+		for _, ioapic := range madt.IOAPICs {
+			fmt.Fprintf(buf, "  IOAPIC  ID:%-4d address:%#x GSI base:%d\n", ioapic.ID, ioapic.Address, ioapic.GSIBase)
+		}
+
+		for _, o := range madt.Overrides {
+			fmt.Fprintf(buf, "  IRQ %-3d bus:%d -> GSI:%d flags:%#x\n", o.Source, o.Bus, o.GSI, o.Flags)
+		}
+	case acpi.MCFGSignature:
+		var entries []*acpi.MCFGEntry
+
+		if entries, err = acpi.ParseMCFG(t); err != nil {
+			return
+		}
+
+		for _, e := range entries {
+			fmt.Fprintf(buf, "  ECAM %#x segment:%d bus:%02x-%02x\n", e.BaseAddress, e.Segment, e.StartBus, e.EndBus)
+		}
+	case acpi.HPETSignature:
+		var hpet *acpi.HPET
+
+		if hpet, err = acpi.ParseHPET(t); err != nil {
+			return
+		}
+
+		fmt.Fprintf(buf, "  Base address .......: %s\n", &hpet.BaseAddress)
+		fmt.Fprintf(buf, "  Number .............: %d\n", hpet.Number)
+		fmt.Fprintf(buf, "  Vendor ID ..........: %#04x\n", hpet.VendorID())
+		fmt.Fprintf(buf, "  Comparators ........: %d (64-bit:%v)\n", hpet.Comparators(), hpet.Counter64())
+		fmt.Fprintf(buf, "  Minimum tick .......: %d\n", hpet.MinimumTick)
+	default:
+		fmt.Fprintf(buf, "  use `acpi dump %s <path>` for offline analysis\n", t.Signature())
+	}
+
+	return
+}
+
+func acpiDumpCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var index int
+
+	if len(arg[1]) > 0 {
+		if index, err = strconv.Atoi(arg[1]); err != nil {
+			return "", fmt.Errorf("invalid index, %v", err)
+		}
+	}
+
+	_, tables, err := acpiTables()
+
+	if err != nil {
+		return
+	}
+
+	found := acpi.Find(tables, arg[0])
+
+	if index < 0 || index >= len(found) {
+		return "", fmt.Errorf("could not find %s table (index %d)", arg[0], index)
+	}
+
+	root, err := x64.UEFI.Root()
+
+	if err != nil {
+		return "", fmt.Errorf("could not open root volume, %v", err)
+	}
+
+	t := found[index]
+	name := strings.ReplaceAll(arg[2], `\`, `/`)
+
+	if err = root.WriteFile(name, t.Data); err != nil {
+		return "", fmt.Errorf("could not write file, %v", err)
+	}
+
+	return fmt.Sprintf("%s (%d bytes) written to %s\n", t.Signature(), len(t.Data), arg[2]), nil
+}
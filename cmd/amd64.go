@@ -8,7 +8,6 @@ package cmd
 import (
 	"bytes"
 	"fmt"
-	"regexp"
 	"runtime"
 	"strconv"
 
@@ -25,36 +24,65 @@ func init() {
 		Name: "info",
 		Help: "device information",
 		Fn:   infoCmd,
+		Data: infoData,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "cpuid",
-		Args:    2,
-		Pattern: regexp.MustCompile(`^cpuid\s+([[:xdigit:]]+) ([[:xdigit:]]+)$`),
-		Syntax:  "<leaf> <subleaf>",
-		Help:    "show CPU capabilities",
-		Fn:      cpuidCmd,
+		Name: "cpuid",
+		Spec: []shell.Arg{
+			{Name: "leaf", Kind: shell.Hex},
+			{Name: "subleaf", Kind: shell.Uint},
+		},
+		Help: "show CPU capabilities",
+		Fn:   cpuidCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "msr",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^msr\s+([[:xdigit:]]+)$`),
-		Syntax:  "<hex addr>",
-		Help:    "read model-specific register",
-		Fn:      msrCmd,
+		Name: "msr",
+		Spec: []shell.Arg{
+			{Name: "hex addr", Kind: shell.Hex},
+		},
+		Help: "read model-specific register",
+		Fn:   msrCmd,
 	})
 
 	shell.Add(shell.Cmd{
 		Name: "lspci",
 		Help: "list PCI devices",
 		Fn:   lspciCmd,
+		Data: lspciData,
 	})
 }
 
-func infoCmd(_ *shell.Interface, _ []string) (string, error) {
-	var res bytes.Buffer
+// Region represents a memory region.
+type Region struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// Info represents the `info` command structured output.
+type Info struct {
+	Runtime   string  `json:"runtime"`
+	RAM       Region  `json:"ram"`
+	DMA       *Region `json:"dma,omitempty"`
+	Text      Region  `json:"text"`
+	Heap      Region  `json:"heap"`
+	HeapAlloc uint64  `json:"heap_alloc"`
+	HeapSys   uint64  `json:"heap_sys"`
+	CPU       string  `json:"cpu"`
+	Cores     int     `json:"cores"`
+	Frequency uint32  `json:"frequency"`
+}
 
+// PCIDevice represents a `lspci` command structured output entry.
+type PCIDevice struct {
+	Bus    int    `json:"bus"`
+	Vendor uint16 `json:"vendor"`
+	Device uint16 `json:"device"`
+	Bar0   uint64 `json:"bar0"`
+}
+
+func infoData(_ *shell.Interface, _ []string) (any, error) {
 	ramStart, ramEnd := runtime.MemRegion()
 	textStart, textEnd := runtime.TextRegion()
 	_, heapStart := runtime.DataRegion()
@@ -62,18 +90,48 @@ func infoCmd(_ *shell.Interface, _ []string) (string, error) {
 	m := &runtime.MemStats{}
 	runtime.ReadMemStats(m)
 
-	fmt.Fprintf(&res, "Runtime ......: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
-	fmt.Fprintf(&res, "RAM ..........: %#08x-%#08x (%d MiB)\n", ramStart, ramEnd, (ramEnd-ramStart)/(1024*1024))
+	info := &Info{
+		Runtime:   fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH),
+		RAM:       Region{uint64(ramStart), uint64(ramEnd)},
+		Text:      Region{uint64(textStart), uint64(textEnd)},
+		Heap:      Region{uint64(heapStart), uint64(ramEnd)},
+		HeapAlloc: m.HeapAlloc,
+		HeapSys:   m.HeapSys,
+		CPU:       x64.AMD64.Name(),
+		Cores:     amd64.NumCPU(),
+		Frequency: x64.AMD64.Freq(),
+	}
 
 	if region := dma.Default(); region != nil {
-		fmt.Fprintf(&res, "DMA ..........: %#08x-%#08x (%d MiB)\n", region.Start(), region.End(), region.Size()/(1024*1024))
+		info.DMA = &Region{uint64(region.Start()), uint64(region.End())}
+	}
+
+	return info, nil
+}
+
+func infoCmd(c *shell.Interface, arg []string) (string, error) {
+	var res bytes.Buffer
+
+	data, err := infoData(c, arg)
+
+	if err != nil {
+		return "", err
+	}
+
+	info := data.(*Info)
+
+	fmt.Fprintf(&res, "Runtime ......: %s\n", info.Runtime)
+	fmt.Fprintf(&res, "RAM ..........: %#08x-%#08x (%d MiB)\n", info.RAM.Start, info.RAM.End, (info.RAM.End-info.RAM.Start)/(1024*1024))
+
+	if info.DMA != nil {
+		fmt.Fprintf(&res, "DMA ..........: %#08x-%#08x (%d MiB)\n", info.DMA.Start, info.DMA.End, (info.DMA.End-info.DMA.Start)/(1024*1024))
 	}
 
-	fmt.Fprintf(&res, "Text .........: %#08x-%#08x\n", textStart, textEnd)
-	fmt.Fprintf(&res, "Heap .........: %#08x-%#08x Alloc:%d MiB Sys:%d MiB\n", heapStart, ramEnd, m.HeapAlloc/(1024*1024), m.HeapSys/(1024*1024))
-	fmt.Fprintf(&res, "CPU ..........: %s\n", x64.AMD64.Name())
-	fmt.Fprintf(&res, "Cores ........: %d\n", amd64.NumCPU())
-	fmt.Fprintf(&res, "Frequency ....: %v GHz\n", float32(x64.AMD64.Freq())/1e9)
+	fmt.Fprintf(&res, "Text .........: %#08x-%#08x\n", info.Text.Start, info.Text.End)
+	fmt.Fprintf(&res, "Heap .........: %#08x-%#08x Alloc:%d MiB Sys:%d MiB\n", info.Heap.Start, info.Heap.End, info.HeapAlloc/(1024*1024), info.HeapSys/(1024*1024))
+	fmt.Fprintf(&res, "CPU ..........: %s\n", info.CPU)
+	fmt.Fprintf(&res, "Cores ........: %d\n", info.Cores)
+	fmt.Fprintf(&res, "Frequency ....: %v GHz\n", float32(info.Frequency)/1e9)
 
 	return res.String(), nil
 }
@@ -116,17 +174,36 @@ func msrCmd(_ *shell.Interface, arg []string) (string, error) {
 	return res.String(), nil
 }
 
-func lspciCmd(_ *shell.Interface, arg []string) (string, error) {
-	var res bytes.Buffer
-
-	fmt.Fprintf(&res, "Bus Vendor Device Bar0\n")
+func pciDevices() (devices []*PCIDevice) {
+	devices = []*PCIDevice{}
 
 	for i := range 256 {
 		for _, d := range pci.Devices(i) {
-			fmt.Fprintf(&res, "%03d %04x   %04x   %#016x\n", i, d.Vendor, d.Device, d.BaseAddress(0))
+			devices = append(devices, &PCIDevice{
+				Bus:    i,
+				Vendor: d.Vendor,
+				Device: d.Device,
+				Bar0:   uint64(d.BaseAddress(0)),
+			})
 		}
 	}
 
+	return
+}
+
+func lspciData(_ *shell.Interface, _ []string) (any, error) {
+	return pciDevices(), nil
+}
+
+func lspciCmd(_ *shell.Interface, _ []string) (string, error) {
+	var res bytes.Buffer
+
+	fmt.Fprintf(&res, "Bus Vendor Device Bar0\n")
+
+	for _, d := range pciDevices() {
+		fmt.Fprintf(&res, "%03d %04x   %04x   %#016x\n", d.Bus, d.Vendor, d.Device, d.Bar0)
+	}
+
 	return res.String(), nil
 }
 
@@ -7,10 +7,10 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
-	"regexp"
 	"runtime"
 	"runtime/debug"
 	"runtime/pprof"
@@ -24,6 +24,10 @@ import (
 const testDiversifier = "\xde\xad\xbe\xef"
 const LogPath = "/go-boot.log"
 
+// HistoryPath represents the command history file on the EFI System
+// Partition.
+var HistoryPath = `\go-boot\history`
+
 var Banner string
 
 func init() {
@@ -43,11 +47,9 @@ func init() {
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "exit,quit",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^(exit|quit)$`),
-		Help:    "exit application",
-		Fn:      exitCmd,
+		Name: "exit,quit",
+		Help: "exit application",
+		Fn:   exitCmd,
 	})
 
 	shell.Add(shell.Cmd{
@@ -57,18 +59,18 @@ func init() {
 	})
 
 	shell.Add(shell.Cmd{
-		Name: "stackall",
-		Help: "goroutine stack trace (all)",
-		Fn:   stackallCmd,
+		Name:   "stackall",
+		Help:   "goroutine stack trace (all)",
+		Stream: stackallCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "date",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^date(?: (.*))?$`),
-		Syntax:  "(time in RFC339 format)?",
-		Help:    "show/change runtime date and time",
-		Fn:      dateCmd,
+		Name: "date",
+		Spec: []shell.Arg{
+			{Name: "time", Kind: shell.Text, Optional: true, Help: "RFC3339 format (e.g. 2006-01-02T15:04:05Z)"},
+		},
+		Help: "show/change runtime date and time",
+		Fn:   dateCmd,
 	})
 
 	shell.Add(shell.Cmd{
@@ -101,11 +103,8 @@ func stackCmd(_ *shell.Interface, _ []string) (string, error) {
 	return string(debug.Stack()), nil
 }
 
-func stackallCmd(_ *shell.Interface, _ []string) (string, error) {
-	buf := new(bytes.Buffer)
-	pprof.Lookup("goroutine").WriteTo(buf, 1)
-
-	return buf.String(), nil
+func stackallCmd(_ context.Context, _ *shell.Interface, w io.Writer, _ []string) error {
+	return pprof.Lookup("goroutine").WriteTo(w, 1)
 }
 
 func dateCmd(_ *shell.Interface, arg []string) (res string, err error) {
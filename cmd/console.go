@@ -0,0 +1,95 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/usbarmory/go-boot/fb"
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi"
+)
+
+// ASCII control characters for line editing
+const (
+	ctrlC = 0x03 // interrupt
+	ctrlE = 0x05 // end of line
+	ctrlU = 0x15 // erase line
+)
+
+// Framebuffer represents the active graphical console, when set it is
+// silenced before exiting EFI Boot Services.
+var Framebuffer *fb.Console
+
+// Consoles represents the console multiplexer, when set input is read from
+// and output mirrored to all its enabled consoles.
+var Consoles *shell.Mux
+
+// Hotkeys represents the UEFI console function key bindings to shell
+// commands, the default entries are launched by the `linux` and `.`
+// commands without arguments.
+var Hotkeys = map[uint16]string{
+	uefi.SCAN_F1: "help",
+	uefi.SCAN_F2: "linux",
+	uefi.SCAN_F3: ".",
+	uefi.SCAN_F4: "windows",
+}
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "console",
+		Spec: []shell.Arg{
+			{Name: "name", Optional: true, Help: "console name"},
+			{Name: "state", Kind: shell.Enum, Values: []string{"on", "off"}, Optional: true, Help: "console state, required with name"},
+		},
+		Help: "list or toggle multiplexed consoles",
+		Fn:   consoleCmd,
+	})
+}
+
+// ConsoleKeys implements a [uefi.Console] key filter to handle hotkeys and
+// control shortcuts not supported by the terminal line editor:
+//
+//	Ctrl-C  interrupts the running command or discards the current line
+//	        (handled by the shell)
+//	Ctrl-L  clears the screen (handled by the line editor)
+//	Fn      executes the command bound in [Hotkeys]
+func ConsoleKeys(k *uefi.KeyData) (buf []byte, ok bool) {
+	// discard the current line
+	kill := []byte{ctrlE, ctrlU}
+
+	if cmd, ok := Hotkeys[k.Key.ScanCode]; ok {
+		return append(kill, cmd+"\r"...), true
+	}
+
+	if r := k.Char(); r == ctrlC || (k.Control() && (r == 'c' || r == 'C')) {
+		return []byte{ctrlC}, true
+	}
+
+	return nil, false
+}
+
+func consoleCmd(c *shell.Interface, arg []string) (res string, err error) {
+	if Consoles == nil {
+		return "", errors.New("console multiplexing not enabled")
+	}
+
+	if len(arg[0]) > 0 {
+		if len(arg[1]) == 0 {
+			return "", errors.New("missing console state")
+		}
+
+		if err = Consoles.Enable(arg[0], arg[1] == "on"); err != nil {
+			return
+		}
+
+		if cols, rows := Consoles.Size(); cols > 0 && c.Terminal != nil {
+			c.Terminal.SetSize(cols, rows)
+		}
+	}
+
+	return Consoles.String(), nil
+}
@@ -0,0 +1,169 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+
+	"github.com/usbarmory/go-boot/edid"
+	"github.com/usbarmory/go-boot/fb"
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "display",
+		Spec: []shell.Arg{
+			{Name: "raw", Kind: shell.Flag, Help: "show raw EDID"},
+		},
+		Help: "show display EDID information",
+		Fn:   displayCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "screenshot",
+		Spec: []shell.Arg{
+			{Name: "path", Help: "output file, BMP format with .bmp extension"},
+		},
+		Help: "save screen capture (PNG or BMP)",
+		Fn:   screenshotCmd,
+	})
+}
+
+// displayEDID returns the active display EDID, or the discovered one when
+// the former is not available.
+func displayEDID() (buf []byte, err error) {
+	if buf, err = x64.UEFI.Boot.GetEDID(true); err == nil {
+		return
+	}
+
+	return x64.UEFI.Boot.GetEDID(false)
+}
+
+func displayInfo(buf *bytes.Buffer, e *edid.EDID) {
+	fmt.Fprintf(buf, "Manufacturer ......: %s (product:%#04x serial:%d)\n", e.Manufacturer, e.ProductCode, e.SerialNumber)
+	fmt.Fprintf(buf, "Name ..............: %s\n", e.Name)
+	fmt.Fprintf(buf, "Serial Number .....: %s\n", e.Serial)
+	fmt.Fprintf(buf, "Manufactured ......: week %d of %d\n", e.Week, e.Year)
+	fmt.Fprintf(buf, "EDID Version ......: %d.%d (%d extensions)\n", e.Version, e.Revision, e.Extensions)
+	fmt.Fprintf(buf, "Physical Size .....: %dx%d cm\n", e.Width, e.Height)
+
+	if t := e.Preferred(); t != nil {
+		x, y := e.DPI()
+		fmt.Fprintf(buf, "Native Resolution .: %s (%dx%d mm, %dx%d dpi)\n", t, t.WidthMM, t.HeightMM, x, y)
+	}
+
+	for i, t := range e.Timings {
+		if i > 0 {
+			fmt.Fprintf(buf, "Detailed Timing ...: %s\n", t)
+		}
+	}
+}
+
+func displayCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+	var found bool
+
+	for _, active := range []bool{true, false} {
+		var e *edid.EDID
+
+		name := "discovered"
+
+		if active {
+			name = "active"
+		}
+
+		data, err := x64.UEFI.Boot.GetEDID(active)
+
+		if err != nil {
+			continue
+		}
+
+		found = true
+		fmt.Fprintf(&buf, "EDID (%s) %s: %d bytes\n", name, strings.Repeat(".", 10-len(name)), len(data))
+
+		if arg[0] == "raw" {
+			fmt.Fprintf(&buf, "%s", hex.Dump(data))
+			continue
+		}
+
+		if e, err = edid.Parse(data); err != nil {
+			fmt.Fprintf(&buf, "could not parse EDID, %v\n", err)
+			continue
+		}
+
+		displayInfo(&buf, e)
+	}
+
+	if gop, err := x64.UEFI.Boot.GetGraphicsOutput(); err == nil {
+		if mode, err := gop.GetMode(); err == nil {
+			if info, err := mode.GetInfo(); err == nil {
+				fmt.Fprintf(&buf, "Graphics Mode .....: %d (%dx%d %s)\n",
+					mode.Mode, info.HorizontalResolution, info.VerticalResolution, info.Format())
+			}
+		}
+	}
+
+	if !found {
+		fmt.Fprintf(&buf, "no EDID information available\n")
+	}
+
+	return buf.String(), nil
+}
+
+func screenshotCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var gop *uefi.GraphicsOutput
+	var mode *uefi.ProtocolMode
+	var info *uefi.ModeInformation
+	var img *image.RGBA
+	var buf bytes.Buffer
+
+	if gop, err = x64.UEFI.Boot.GetGraphicsOutput(); err != nil {
+		return "", fmt.Errorf("could not locate graphics output, %v", err)
+	}
+
+	if mode, err = gop.GetMode(); err != nil {
+		return
+	}
+
+	if info, err = mode.GetInfo(); err != nil {
+		return
+	}
+
+	w := int(info.HorizontalResolution)
+	h := int(info.VerticalResolution)
+
+	if img, err = fb.Capture(gop, 0, 0, w, h); err != nil {
+		return "", fmt.Errorf("could not capture screen, %v", err)
+	}
+
+	if strings.HasSuffix(strings.ToLower(arg[0]), ".bmp") {
+		buf.Write(fb.EncodeBMP(img))
+	} else if err = png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("could not encode image, %v", err)
+	}
+
+	root, err := x64.UEFI.Root()
+
+	if err != nil {
+		return "", fmt.Errorf("could not open root volume, %v", err)
+	}
+
+	name := strings.ReplaceAll(arg[0], `\`, `/`)
+
+	if err = root.WriteFile(name, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("could not write file, %v", err)
+	}
+
+	return fmt.Sprintf("%dx%d screen (%d bytes) written to %s\n", w, h, buf.Len(), arg[0]), nil
+}
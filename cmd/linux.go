@@ -9,11 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"regexp"
+	"math/bits"
 
 	"github.com/u-root/u-root/pkg/boot/bzimage"
 
 	"github.com/usbarmory/armory-boot/exec"
+	"github.com/usbarmory/go-boot/edid"
 	"github.com/usbarmory/go-boot/shell"
 	"github.com/usbarmory/go-boot/uapi"
 	"github.com/usbarmory/go-boot/uefi"
@@ -26,6 +27,8 @@ const (
 	minLoadAddr = 0x01000000
 	paramsSize  = 0x1000
 	exitRetries = 3
+	// boot_params.edid_info offset
+	edidInfoOffset = 0x140
 )
 
 // DefaultLinuxEntry represents the default path for the UAPI Type #1 Boot
@@ -33,24 +36,36 @@ const (
 var DefaultLinuxEntry string
 
 func init() {
-	shell.Add(shell.Cmd{
-		Name:    "linux,l",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^(?:linux|l)(?: (\S+))?$`),
-		Syntax:  "(loader entry path)?",
-		Help:    "boot Linux kernel image",
-		Fn:      linuxCmd,
+	addLinuxCmd()
+
+	AddSetting("linux", Setting{
+		Help:  "default UAPI Boot Loader Entry",
+		Value: func() string { return DefaultLinuxEntry },
+		Apply: func(_ *shell.Interface, value string) error {
+			DefaultLinuxEntry = value
+			addLinuxCmd()
+			return nil
+		},
 	})
+}
 
+func addLinuxCmd() {
+	name := "linux,l"
+
+	// boot default entry on empty line
 	if len(DefaultLinuxEntry) > 0 {
-		shell.Add(shell.Cmd{
-			Name:    "linux,l,\\r",
-			Args:    1,
-			Pattern: regexp.MustCompile(`^(?:linux|l|)(?: (\S+))?$`),
-			Help:    fmt.Sprintf("`l %s`", DefaultLinuxEntry),
-			Fn:      linuxCmd,
-		})
+		name += ",\\r"
 	}
+
+	shell.DefaultCommands.Remove("linux")
+	shell.Add(shell.Cmd{
+		Name: name,
+		Spec: []shell.Arg{
+			{Name: "loader entry path", Optional: true, Default: DefaultLinuxEntry},
+		},
+		Help: "boot Linux kernel image",
+		Fn:   linuxCmd,
+	})
 }
 
 func reserveMemory(m *uefi.MemoryMap, image *exec.LinuxImage) (err error) {
@@ -127,6 +142,18 @@ func efiInfo(memoryMap *uefi.MemoryMap) (efi *exec.EFI, err error) {
 	}, nil
 }
 
+// maskBits returns the size and position of a pixel color mask.
+func maskBits(mask uint32) (size uint8, pos uint8) {
+	if mask == 0 {
+		return
+	}
+
+	pos = uint8(bits.TrailingZeros32(mask))
+	size = uint8(bits.Len32(mask >> pos))
+
+	return
+}
+
 func screenInfo() (screen *exec.Screen, err error) {
 	var gop *uefi.GraphicsOutput
 	var mode *uefi.ProtocolMode
@@ -144,17 +171,30 @@ func screenInfo() (screen *exec.Screen, err error) {
 		return
 	}
 
-	// values for efib selection
+	if info.PixelFormat >= uefi.PixelBltOnly {
+		return nil, fmt.Errorf("unsupported pixel format (%s)", info.Format())
+	}
+
+	red, green, blue, reserved := info.Masks()
+	depth := bits.Len32(red | green | blue | reserved)
+
+	// values for efifb selection
 	screen = &exec.Screen{
 		OrigVideoIsVGA: exec.VideoTypeEFI,
 		LfbWidth:       uint16(info.HorizontalResolution),
 		LfbHeight:      uint16(info.VerticalResolution),
+		LfbDepth:       uint16(depth),
 		LfbBase:        uint32(mode.FrameBufferBase),
 		LfbSize:        uint32(mode.FrameBufferSize),
-		LfbLineLength:  uint16(info.HorizontalResolution * 4),
+		LfbLineLength:  uint16(info.PixelsPerScanLine * uint32((depth+7)/8)),
 		ExtLfbBase:     uint32(mode.FrameBufferBase >> 32),
 	}
 
+	screen.RedSize, screen.RedPos = maskBits(red)
+	screen.GreenSize, screen.GreenPos = maskBits(green)
+	screen.BlueSize, screen.BluePos = maskBits(blue)
+	screen.RsvdSize, screen.RsvdPos = maskBits(reserved)
+
 	if screen.ExtLfbBase > 0 {
 		screen.Capabilities = exec.Video64BitBase
 	}
@@ -172,6 +212,13 @@ func boot(image *exec.LinuxImage) (err error) {
 		log.Printf("could not detect screen information, %v\n", err)
 	}
 
+	// read EDID for edid_info
+	edidInfo, err := displayEDID()
+
+	if err != nil || len(edidInfo) < edid.BlockSize {
+		edidInfo = nil
+	}
+
 	for range exitRetries {
 		// own all available memory
 		if memoryMap, err = x64.UEFI.Boot.ExitBootServices(); err != nil {
@@ -189,6 +236,16 @@ func boot(image *exec.LinuxImage) (err error) {
 	x64.Console.Out = 0
 	x64.UEFI.Console.Out = 0
 
+	// silence framebuffer console
+	if Framebuffer != nil {
+		Framebuffer.GOP = nil
+	}
+
+	// silence multiplexed consoles, which might rely on boot services
+	if Consoles != nil {
+		x64.Stdout = nil
+	}
+
 	// parse kernel image
 	if err = image.Parse(); err != nil {
 		return
@@ -212,6 +269,11 @@ func boot(image *exec.LinuxImage) (err error) {
 		return fmt.Errorf("could not load kernel, %v", err)
 	}
 
+	// fill edid_info
+	if edidInfo != nil {
+		image.Region.Write(image.Region.Start(), image.ParamsOffset+edidInfoOffset, edidInfo[:edid.BlockSize])
+	}
+
 	log.Printf("booting kernel@%#x", image.Entry())
 	return image.Boot(nil)
 }
@@ -225,6 +287,10 @@ func linuxCmd(_ *shell.Interface, arg []string) (res string, err error) {
 		path = DefaultLinuxEntry
 	}
 
+	if err = approved(path); err != nil {
+		return
+	}
+
 	if x64.UEFI.Boot == nil {
 		return "", errors.New("EFI Boot Services unavailable")
 	}
@@ -251,5 +317,12 @@ func linuxCmd(_ *shell.Interface, arg []string) (res string, err error) {
 		CmdLine:        entry.Options,
 	}
 
+	if err = measureLinux(image); err != nil {
+		return "", fmt.Errorf("could not measure kernel, %v", err)
+	}
+
+	// pass unsealed secrets, after measurement
+	image.InitialRamDisk = appendCredentials(image.InitialRamDisk)
+
 	return "", boot(image)
 }
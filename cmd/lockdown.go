@@ -0,0 +1,88 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+// Lockdown enables lockdown mode when set to `1` at compile time.
+var Lockdown string
+
+// ApprovedEntries represents the comma separated list of Boot Loader Entries
+// and EFI images allowed to boot in lockdown mode, when empty only the
+// default ones are allowed (see DefaultLinuxEntry, DefaultEFIEntry).
+var ApprovedEntries string
+
+// LockdownCommands represents the commands not available in lockdown mode.
+var LockdownCommands = []string{
+	"peek", "poke", "msr", "set", "unset", "alias",
+	// EFI System Partition writes
+	"acpi dump", "screenshot", "tpm seal",
+	// EFI System Partition scripts
+	"source",
+}
+
+// Locked returns whether lockdown mode is enabled.
+func Locked() bool {
+	return Lockdown == "1"
+}
+
+// EnforceLockdown removes the commands not available in lockdown mode, EFI
+// image loading is only retained when UEFI Secure Boot is enabled as it
+// ensures that unsigned images are rejected.
+func EnforceLockdown() {
+	shell.DefaultCommands.Remove(LockdownCommands...)
+
+	if !secureBoot() {
+		shell.DefaultCommands.Remove(".", "windows")
+	}
+}
+
+// secureBoot returns whether UEFI Secure Boot is enabled.
+func secureBoot() bool {
+	_, data, err := x64.UEFI.Runtime.GetVariable("SecureBoot", uefi.EFI_GLOBAL_VARIABLE_GUID, true)
+	return err == nil && len(data) > 0 && data[0] == 1
+}
+
+// approved returns an error if the argument entry cannot be booted in
+// lockdown mode.
+func approved(path string) error {
+	if !Locked() {
+		return nil
+	}
+
+	entries := []string{DefaultLinuxEntry, DefaultEFIEntry}
+
+	if len(ApprovedEntries) > 0 {
+		entries = strings.Split(ApprovedEntries, ",")
+	}
+
+	// FAT paths are case insensitive
+	for _, entry := range entries {
+		if e := strings.TrimSpace(entry); len(e) > 0 && strings.EqualFold(e, path) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not an approved entry", path)
+}
+
+// verifiedImage returns an error if EFI image loading cannot reject unsigned
+// images in lockdown mode.
+func verifiedImage() error {
+	if Locked() && !secureBoot() {
+		return errors.New("UEFI Secure Boot disabled, unsigned images cannot be rejected")
+	}
+
+	return nil
+}
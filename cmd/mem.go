@@ -6,10 +6,11 @@
 package cmd
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
-	"regexp"
+	"io"
 	"strconv"
 
 	"github.com/usbarmory/go-boot/shell"
@@ -20,21 +21,23 @@ const maxBufferSize = 102400
 
 func init() {
 	shell.Add(shell.Cmd{
-		Name:    "peek",
-		Args:    2,
-		Pattern: regexp.MustCompile(`^peek ([[:xdigit:]]+) (\d+)$`),
-		Syntax:  "<hex addr> <size>",
-		Help:    "memory display (use with caution)",
-		Fn:      memReadCmd,
+		Name: "peek",
+		Spec: []shell.Arg{
+			{Name: "hex addr", Kind: shell.Hex},
+			{Name: "size", Kind: shell.Uint},
+		},
+		Help:   "memory display (use with caution)",
+		Stream: memReadCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "poke",
-		Args:    2,
-		Pattern: regexp.MustCompile(`^poke ([[:xdigit:]]+) ([[:xdigit:]]+)$`),
-		Syntax:  "<hex addr> <hex value>",
-		Help:    "memory write   (use with caution)",
-		Fn:      memWriteCmd,
+		Name: "poke",
+		Spec: []shell.Arg{
+			{Name: "hex addr", Kind: shell.Hex},
+			{Name: "hex value", Kind: shell.Hex},
+		},
+		Help: "memory write   (use with caution)",
+		Fn:   memWriteCmd,
 	})
 }
 
@@ -58,28 +61,40 @@ func memCopy(start uint, size int, w []byte) (b []byte) {
 	return
 }
 
-func memReadCmd(_ *shell.Interface, arg []string) (res string, err error) {
+func memReadCmd(ctx context.Context, _ *shell.Interface, w io.Writer, arg []string) (err error) {
 	addr, err := strconv.ParseUint(arg[0], 16, dma.DefaultAlignment*8)
 
 	if err != nil {
-		return "", fmt.Errorf("invalid address, %v", err)
+		return fmt.Errorf("invalid address, %v", err)
 	}
 
 	size, err := strconv.ParseUint(arg[1], 10, 32)
 
 	if err != nil {
-		return "", fmt.Errorf("invalid size, %v", err)
+		return fmt.Errorf("invalid size, %v", err)
 	}
 
 	if (addr%dma.DefaultAlignment) != 0 || (size%dma.DefaultAlignment) != 0 {
-		return "", fmt.Errorf("only %d-bit aligned accesses are supported", dma.DefaultAlignment*8)
+		return fmt.Errorf("only %d-bit aligned accesses are supported", dma.DefaultAlignment*8)
 	}
 
-	if size > maxBufferSize {
-		return "", fmt.Errorf("size argument must be <= %d", maxBufferSize)
+	dump := hex.Dumper(w)
+	defer dump.Close()
+
+	// copy in chunks to allow interruption of large ranges
+	for off := uint64(0); off < size; off += maxBufferSize {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		n := min(size-off, maxBufferSize)
+
+		if _, err = dump.Write(memCopy(uint(addr+off), int(n), nil)); err != nil {
+			return
+		}
 	}
 
-	return hex.Dump(memCopy(uint(addr), int(size), nil)), nil
+	return
 }
 
 func memWriteCmd(_ *shell.Interface, arg []string) (res string, err error) {
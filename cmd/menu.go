@@ -0,0 +1,278 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/usbarmory/go-boot/fb"
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+// MenuPath represents the default graphical boot menu configuration path.
+var MenuPath = `\go-boot\menu.conf`
+
+// menu defaults
+const (
+	menuEntries    = `\loader\entries`
+	menuBackground = 0x000000
+	menuForeground = 0xffffff
+	menuHighlight  = 0x3465a4
+)
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "menu",
+		Spec: []shell.Arg{
+			{Name: "path", Optional: true, Help: "configuration file (default: " + MenuPath + ")"},
+		},
+		Help: "graphical boot menu",
+		Fn:   menuCmd,
+	})
+}
+
+type menuItem struct {
+	name string
+	cmd  string
+}
+
+// menuConfig parses the graphical boot menu configuration, each line
+// specifies a key followed by its value:
+//
+//	timeout    <seconds>
+//	default    <entry file name or title>
+//	splash     <image path>
+//	background <RRGGBB>
+//	foreground <RRGGBB>
+//	highlight  <RRGGBB>
+//	entries    <UAPI Type #1 entries directory>
+//	entry      <title>|<command>
+//	icon       <image path> <entry file name or title>
+//
+// Images can be in PNG or BMP format.
+func menuConfig(root *uefi.FS, conf string, m *fb.Menu) (items []*menuItem, err error) {
+	var def string
+
+	dir := menuEntries
+	icons := make(map[string]string)
+	custom := []*menuItem{}
+
+	m.Background = menuBackground
+	m.Foreground = menuForeground
+	m.Highlight = menuHighlight
+
+	for line := range strings.Lines(conf) {
+		kv := strings.SplitN(strings.TrimSpace(line), " ", 2)
+
+		if len(kv) < 2 || strings.HasPrefix(kv[0], "#") {
+			continue
+		}
+
+		k := kv[0]
+		v := strings.TrimSpace(kv[1])
+
+		switch k {
+		case "timeout":
+			var n int
+
+			if n, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("invalid timeout, %v", err)
+			}
+
+			m.Timeout = time.Duration(n) * time.Second
+		case "default":
+			def = v
+		case "splash":
+			if m.Splash, err = loadImage(root, v); err != nil {
+				return
+			}
+		case "background":
+			m.Background, err = fb.ParseColor(v)
+		case "foreground":
+			m.Foreground, err = fb.ParseColor(v)
+		case "highlight":
+			m.Highlight, err = fb.ParseColor(v)
+		case "entries":
+			dir = v
+		case "entry":
+			title, cmd, ok := strings.Cut(v, "|")
+
+			if !ok {
+				return nil, fmt.Errorf("invalid entry %s", v)
+			}
+
+			custom = append(custom, &menuItem{
+				name: strings.TrimSpace(title),
+				cmd:  strings.TrimSpace(cmd),
+			})
+		case "icon":
+			p, name, _ := strings.Cut(v, " ")
+			icons[strings.TrimSpace(name)] = p
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	entries, _ := fs.ReadDir(root, strings.ReplaceAll(dir, `\`, `/`))
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".conf") {
+			continue
+		}
+
+		p := dir + `\` + e.Name()
+		title := entryTitle(root, p)
+
+		m.Entries = append(m.Entries, &fb.MenuEntry{Title: title})
+		items = append(items, &menuItem{
+			name: e.Name(),
+			cmd:  "linux " + p,
+		})
+
+		if strings.HasSuffix(DefaultLinuxEntry, `\`+e.Name()) && len(def) == 0 {
+			m.Default = len(items) - 1
+		}
+	}
+
+	for _, item := range custom {
+		m.Entries = append(m.Entries, &fb.MenuEntry{Title: item.name})
+		items = append(items, item)
+	}
+
+	for i, item := range items {
+		name := item.name
+
+		if len(icons[name]) == 0 {
+			name = m.Entries[i].Title
+		}
+
+		if p, ok := icons[name]; ok {
+			if m.Entries[i].Icon, err = loadImage(root, p); err != nil {
+				return
+			}
+		}
+
+		if def == item.name || def == m.Entries[i].Title {
+			m.Default = i
+		}
+	}
+
+	return
+}
+
+// entryTitle returns the title of a Type #1 Boot Loader Entry, without
+// loading its contents.
+func entryTitle(root *uefi.FS, p string) string {
+	buf, err := fs.ReadFile(root, strings.ReplaceAll(p, `\`, `/`))
+
+	if err != nil {
+		return path.Base(p)
+	}
+
+	for line := range strings.Lines(string(buf)) {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "title "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+
+	return strings.TrimSuffix(path.Base(strings.ReplaceAll(p, `\`, `/`)), ".conf")
+}
+
+func loadImage(root *uefi.FS, p string) (img image.Image, err error) {
+	buf, err := fs.ReadFile(root, strings.ReplaceAll(p, `\`, `/`))
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read image, %v", err)
+	}
+
+	if img, err = fb.Decode(buf); err != nil {
+		return nil, fmt.Errorf("could not decode image %s, %v", p, err)
+	}
+
+	return
+}
+
+func menuCmd(c *shell.Interface, arg []string) (res string, err error) {
+	var conf []byte
+	var items []*menuItem
+
+	p := arg[0]
+
+	if len(p) == 0 {
+		p = MenuPath
+	}
+
+	root, err := x64.UEFI.Root()
+
+	if err != nil {
+		return "", fmt.Errorf("could not open root volume, %v", err)
+	}
+
+	if conf, err = fs.ReadFile(root, strings.ReplaceAll(p, `\`, `/`)); err != nil && len(arg[0]) > 0 {
+		return "", fmt.Errorf("could not read menu configuration, %v", err)
+	}
+
+	m := &fb.Menu{
+		Input: c.Input(),
+		Font:  fb.DefaultFont,
+	}
+
+	if Framebuffer != nil {
+		m.Font = Framebuffer.Font
+	}
+
+	if items, err = menuConfig(root, string(conf), m); err != nil {
+		return
+	}
+
+	if len(items) == 0 {
+		return "", errors.New("no boot entries found")
+	}
+
+	if m.GOP, err = x64.UEFI.Boot.GetGraphicsOutput(); err != nil {
+		return "", fmt.Errorf("could not locate graphics output, %v", err)
+	}
+
+	if m.Pointer, err = x64.UEFI.Boot.GetSimplePointer(); err != nil {
+		m.Pointer = nil
+	}
+
+	// silence the framebuffer console while the menu owns the screen
+	if Framebuffer != nil {
+		Framebuffer.GOP = nil
+	}
+
+	i, err := m.Run()
+
+	// restore console
+	if Framebuffer != nil {
+		Framebuffer.GOP = m.GOP
+		Framebuffer.Init()
+	} else {
+		x64.UEFI.Console.ClearScreen()
+	}
+
+	if err != nil || i < 0 {
+		return
+	}
+
+	log.Printf("menu selected %s (%s)", m.Entries[i].Title, items[i].cmd)
+	c.Exec([]byte(items[i].cmd))
+
+	return
+}
@@ -10,13 +10,10 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
-	"os"
-	"regexp"
 	"strings"
 
 	"github.com/gliderlabs/ssh"
@@ -33,30 +30,59 @@ import (
 // Resolver represents the default name server
 var Resolver = "8.8.8.8:53"
 
+// SSHCommands represents the commands available on SSH sessions, any other
+// command is not (e.g. `efivar`, which would expose the [PasswordVariable]
+// hash).
+var SSHCommands = []string{
+	"info", "build", "uptime", "log", "stack", "stackall",
+	"uefi", "memmap", "protocol", "acpi", "lspci", "cpuid", "sysinfo", "display",
+	"ls", "stat", "cat",
+	"tpm pcrs", "eventlog",
+	"dns", "echo", "sleep", "clear", "exit",
+}
+
 const receiveMask = uefi.EFI_SIMPLE_NETWORK_RECEIVE_UNICAST |
 	uefi.EFI_SIMPLE_NETWORK_RECEIVE_BROADCAST |
 	uefi.EFI_SIMPLE_NETWORK_RECEIVE_PROMISCUOUS
 
 func init() {
 	shell.Add(shell.Cmd{
-		Name:    "net",
-		Args:    4,
-		Pattern: regexp.MustCompile(`^net (\S+) (\S+) (\S+)( debug)?$`),
-		Syntax:  "<ip> <mac> <gw> (debug)?",
-		Help:    "start UEFI networking",
-		Fn:      netCmd,
+		Name: "net",
+		Spec: []shell.Arg{
+			{Name: "ip", Help: "address in CIDR notation (e.g. 10.0.0.1/24)"},
+			{Name: "mac", Help: "hardware address, `:` for the interface one"},
+			{Name: "gw", Help: "gateway address"},
+			{Name: "debug", Kind: shell.Flag, Help: "start pprof and SSH servers"},
+		},
+		Help: "start UEFI networking",
+		Fn:   netCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "dns",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^dns (.*)`),
-		Syntax:  "<host>",
-		Help:    "resolve domain",
-		Fn:      dnsCmd,
+		Name: "dns",
+		Spec: []shell.Arg{
+			{Name: "host", Kind: shell.Text},
+		},
+		Help: "resolve domain",
+		Fn:   dnsCmd,
 	})
 
 	net.SetDefaultNS([]string{Resolver})
+
+	AddSetting("resolver", Setting{
+		Help:  "name server address (host:port)",
+		Value: func() string { return Resolver },
+		Apply: func(_ *shell.Interface, value string) (err error) {
+			if _, _, err = net.SplitHostPort(value); err != nil {
+				return
+			}
+
+			Resolver = value
+			net.SetDefaultNS([]string{Resolver})
+
+			return
+		},
+	})
 }
 
 func netCmd(_ *shell.Interface, arg []string) (res string, err error) {
@@ -118,15 +144,25 @@ func netCmd(_ *shell.Interface, arg []string) (res string, err error) {
 		log.Printf("\thttp://%s:80/debug/pprof\n", ip)
 		log.Printf("\tssh://%s:22\n", ip)
 
+		// SSH sessions are never multiplexed (see [Consoles]) as the
+		// shared shell cannot enforce their restrictions.
 		ssh.Handle(func(s ssh.Session) {
 			c := &shell.Interface{
 				Banner:     Banner,
 				ReadWriter: s,
+				LogSink:    s,
+				Commands:   shell.DefaultCommands.Only(SSHCommands...),
+				Lockdown:   Locked(),
 			}
 
-			log.SetOutput(io.MultiWriter(os.Stdout, s))
-			defer log.SetOutput(os.Stdout)
+			auth, err := Authenticator()
+
+			if err != nil {
+				log.Printf("could not load password, %v", err)
+				return
+			}
 
+			c.Authenticate = auth
 			c.Start(true)
 		})
 
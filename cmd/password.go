@@ -0,0 +1,122 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+// PasswordHash represents the console password Argon2id hash, in PHC string
+// format (e.g. `$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>`), set at
+// compile time.
+var PasswordHash string
+
+// PasswordVariable represents the UEFI variable name, under VendorGUID, for
+// the console password Argon2id hash, used when PasswordHash is not set. The
+// variable is only honored when not accessible at runtime (i.e. it cannot be
+// set by the operating system).
+const PasswordVariable = "GoBootPassword"
+
+// maximum Argon2 memory parameter (KiB), bounding heap usage on each
+// verification
+const maxArgon2Memory = 256 * 1024
+
+type argon2Hash struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func parseHash(s string) (h *argon2Hash, err error) {
+	var version int
+
+	h = &argon2Hash{}
+	fields := strings.Split(s, "$")
+
+	if len(fields) != 6 || fields[1] != "argon2id" {
+		return nil, errors.New("invalid Argon2id hash format")
+	}
+
+	if _, err = fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errors.New("unsupported Argon2 version")
+	}
+
+	if _, err = fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &h.memory, &h.time, &h.threads); err != nil {
+		return nil, fmt.Errorf("invalid Argon2 parameters, %v", err)
+	}
+
+	switch {
+	case h.time < 1:
+		return nil, errors.New("invalid Argon2 parameters, t must be at least 1")
+	case h.threads < 1:
+		return nil, errors.New("invalid Argon2 parameters, p must be at least 1")
+	case h.memory > maxArgon2Memory:
+		return nil, fmt.Errorf("invalid Argon2 parameters, m exceeds %d KiB", maxArgon2Memory)
+	}
+
+	if h.salt, err = base64.RawStdEncoding.DecodeString(fields[4]); err != nil {
+		return nil, fmt.Errorf("invalid salt, %v", err)
+	}
+
+	if h.key, err = base64.RawStdEncoding.DecodeString(fields[5]); err != nil || len(h.key) == 0 {
+		return nil, fmt.Errorf("invalid hash, %v", err)
+	}
+
+	return
+}
+
+// passwordHash returns the console password hash, if any.
+func passwordHash() (hash string, err error) {
+	if len(PasswordHash) > 0 {
+		return PasswordHash, nil
+	}
+
+	attr, data, err := x64.UEFI.Runtime.GetVariable(PasswordVariable, VendorGUID, true)
+
+	if err != nil {
+		// no password variable
+		return "", nil
+	}
+
+	if attr.RuntimeServiceAccess || !attr.NonVolatile {
+		return "", fmt.Errorf("%s variable must be non-volatile and boot services only", PasswordVariable)
+	}
+
+	return strings.TrimRight(string(data), "\x00\r\n"), nil
+}
+
+// Authenticator returns a console password verification function, or nil
+// when no password is configured.
+func Authenticator() (fn func(password string) bool, err error) {
+	s, err := passwordHash()
+
+	if err != nil || len(s) == 0 {
+		return
+	}
+
+	h, err := parseHash(s)
+
+	if err != nil {
+		return
+	}
+
+	fn = func(password string) bool {
+		key := argon2.IDKey([]byte(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.key)))
+		return subtle.ConstantTimeCompare(key, h.key) == 1
+	}
+
+	return
+}
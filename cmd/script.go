@@ -0,0 +1,111 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+// StartupPath represents the script automatically executed before
+// interactive shell operation.
+var StartupPath = `\go-boot\startup.gbsh`
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "source",
+		Spec: []shell.Arg{
+			{Name: "path", Help: "script file"},
+		},
+		Help: "execute script file",
+		Fn:   sourceCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "echo",
+		Spec: []shell.Arg{
+			{Name: "text", Kind: shell.Text, Optional: true},
+		},
+		Help: "print text",
+		Fn:   echoCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "sleep",
+		Spec: []shell.Arg{
+			{Name: "seconds|duration", Help: "seconds or Go duration (e.g. 500ms)"},
+		},
+		Help:   "pause execution",
+		Stream: sleepCmd,
+	})
+}
+
+func readScript(p string) (script string, err error) {
+	root, err := x64.UEFI.Root()
+
+	if err != nil {
+		return "", fmt.Errorf("could not open root volume, %v", err)
+	}
+
+	buf, err := fs.ReadFile(root, strings.ReplaceAll(p, `\`, `/`))
+
+	if err != nil {
+		return "", fmt.Errorf("could not read script, %v", err)
+	}
+
+	return string(buf), nil
+}
+
+// StartupScript returns the startup script contents, or an empty string if
+// not present.
+func StartupScript() string {
+	script, _ := readScript(StartupPath)
+	return script
+}
+
+func sourceCmd(c *shell.Interface, arg []string) (res string, err error) {
+	script, err := readScript(arg[0])
+
+	if err != nil {
+		return
+	}
+
+	return "", c.Run(script)
+}
+
+func echoCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	return arg[0], nil
+}
+
+func sleepCmd(ctx context.Context, _ *shell.Interface, _ io.Writer, arg []string) (err error) {
+	d, err := time.ParseDuration(arg[0])
+
+	if err != nil {
+		n, e := strconv.ParseUint(arg[0], 10, 32)
+
+		if e != nil {
+			return fmt.Errorf("invalid duration, %v", err)
+		}
+
+		d = time.Duration(n) * time.Second
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+	}
+
+	return
+}
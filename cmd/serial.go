@@ -0,0 +1,156 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+// EFI_PARITY_TYPE characters
+const parityTypes = "DNEOMS"
+
+// EFI_STOP_BITS_TYPE strings
+var stopBitsTypes = []string{"", "1", "1.5", "2"}
+
+// data bits, parity and stop bits (e.g. 8N1)
+var serialMode = regexp.MustCompile(`^([5-8])([NEOMS])(1|1\.5|2)$`)
+
+// serial control signals
+var serialControl = []struct {
+	bit  uint32
+	name string
+}{
+	{uefi.EFI_SERIAL_DATA_TERMINAL_READY, "DTR"},
+	{uefi.EFI_SERIAL_REQUEST_TO_SEND, "RTS"},
+	{uefi.EFI_SERIAL_CLEAR_TO_SEND, "CTS"},
+	{uefi.EFI_SERIAL_DATA_SET_READY, "DSR"},
+	{uefi.EFI_SERIAL_RING_INDICATE, "RI"},
+	{uefi.EFI_SERIAL_CARRIER_DETECT, "CD"},
+	{uefi.EFI_SERIAL_HARDWARE_FLOW_CONTROL_ENABLE, "HWFC"},
+}
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "serial",
+		Spec: []shell.Arg{
+			{Name: "index", Kind: shell.Uint, Optional: true, Help: "serial port index"},
+			{Name: "baud", Kind: shell.Uint, Optional: true, Help: "baud rate, required with index"},
+			{Name: "mode", Optional: true, Help: "data bits, parity and stop bits (e.g. 8N1)"},
+		},
+		Help: "list or configure EFI serial ports",
+		Fn:   serialCmd,
+	})
+}
+
+// SerialDevice returns the EFI Serial I/O Protocol instance at the argument
+// index, as enumerated by the firmware.
+func SerialDevice(index int) (sio *uefi.SerialIO, err error) {
+	devices, err := x64.UEFI.Boot.GetSerialIO()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not locate serial ports, %v", err)
+	}
+
+	if index < 0 || index >= len(devices) {
+		return nil, fmt.Errorf("invalid serial port index %d", index)
+	}
+
+	return devices[index], nil
+}
+
+func serialInfo(buf *bytes.Buffer, i int, sio *uefi.SerialIO) {
+	var signals []string
+
+	m, err := sio.GetMode()
+
+	if err != nil {
+		fmt.Fprintf(buf, "%-6d %#x %v\n", i, sio.Handle, err)
+		return
+	}
+
+	settings := fmt.Sprintf("%d%c%s", m.DataBits, parityTypes[min(int(m.Parity), len(parityTypes)-1)], stopBitsTypes[min(int(m.StopBits), len(stopBitsTypes)-1)])
+
+	if control, err := sio.GetControl(); err == nil {
+		for _, c := range serialControl {
+			if control&c.bit != 0 {
+				signals = append(signals, c.name)
+			}
+		}
+	}
+
+	fmt.Fprintf(buf, "%-6d %#-12x %-8d %-6s %s\n", i, sio.Handle, m.BaudRate, settings, strings.Join(signals, " "))
+}
+
+func serialCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+	var devices []*uefi.SerialIO
+
+	if len(arg[0]) > 0 {
+		var sio *uefi.SerialIO
+		var m *uefi.SerialMode
+		var baud uint64
+
+		index, _ := strconv.Atoi(arg[0])
+
+		if len(arg[1]) == 0 {
+			return "", errors.New("missing baud rate")
+		}
+
+		if sio, err = SerialDevice(index); err != nil {
+			return
+		}
+
+		if m, err = sio.GetMode(); err != nil {
+			return
+		}
+
+		if baud, err = strconv.ParseUint(arg[1], 10, 64); err != nil {
+			return "", fmt.Errorf("invalid baud rate, %v", err)
+		}
+
+		dataBits := uint8(m.DataBits)
+		parity := m.Parity
+		stopBits := m.StopBits
+
+		if len(arg[2]) > 0 {
+			mode := serialMode.FindStringSubmatch(arg[2])
+
+			if mode == nil {
+				return "", fmt.Errorf("invalid mode %s", arg[2])
+			}
+
+			dataBits = mode[1][0] - '0'
+			parity = uint32(strings.Index(parityTypes, mode[2]))
+			stopBits = uint32(slices.Index(stopBitsTypes, mode[3]))
+		}
+
+		if err = sio.SetAttributes(baud, m.ReceiveFifoDepth, m.Timeout, parity, dataBits, stopBits); err != nil {
+			return "", fmt.Errorf("could not set serial attributes, %v", err)
+		}
+	}
+
+	if devices, err = x64.UEFI.Boot.GetSerialIO(); err != nil {
+		return "", fmt.Errorf("could not locate serial ports, %v", err)
+	}
+
+	fmt.Fprintf(&buf, "%-6s %-12s %-8s %-6s %s\n", "Index", "Handle", "Baud", "Mode", "Control")
+
+	for i, sio := range devices {
+		serialInfo(&buf, i, sio)
+	}
+
+	return buf.String(), nil
+}
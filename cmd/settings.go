@@ -0,0 +1,334 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+// VendorGUID represents the go-boot UEFI variables vendor GUID.
+var VendorGUID = uefi.MustParseGUID("3afb3806-894d-4b37-a84e-68bb232eb516")
+
+// SettingsVariable represents the UEFI variable name, under VendorGUID, for
+// persisted settings and aliases.
+const SettingsVariable = "GoBootSettings"
+
+// SettingsPath represents the EFI System Partition file for persisted
+// settings and aliases, used when the UEFI variable cannot be written.
+var SettingsPath = `\go-boot\settings`
+
+// Console represents the console preference, its default value is set at
+// compile time (see _Compiling_) and it takes effect at the next boot.
+var Console string
+
+// consoles represents the valid Console values
+var consoles = []string{"com1", "serial", "all", "fb", "text"}
+
+// Setting represents a runtime setting, persisted across boots with the
+// `set` command.
+type Setting struct {
+	// Help defines the `set` command listing description.
+	Help string
+	// Value returns the current setting value.
+	Value func() string
+	// Apply changes the setting value, the interface argument is nil when
+	// settings are loaded at boot.
+	Apply func(c *shell.Interface, value string) error
+
+	// compile time value, restored by `unset`
+	defaultValue string
+	// persisted value
+	value *string
+}
+
+var settings = make(map[string]*Setting)
+
+// AddSetting registers a runtime setting.
+func AddSetting(name string, s Setting) {
+	settings[name] = &s
+}
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "set",
+		Spec: []shell.Arg{
+			{Name: "name", Optional: true},
+			{Name: "value", Kind: shell.Text, Optional: true},
+		},
+		Help: "list or persist settings",
+		Fn:   setCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "unset",
+		Spec: []shell.Arg{
+			{Name: "alias", Kind: shell.Flag, Help: "remove alias rather than setting"},
+			{Name: "name"},
+		},
+		Help: "restore setting default or remove alias",
+		Fn:   unsetCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "alias",
+		Spec: []shell.Arg{
+			{Name: "name", Optional: true},
+			{Name: "command", Kind: shell.Text, Optional: true},
+		},
+		Help: "list or persist command aliases",
+		Fn:   aliasCmd,
+	})
+
+	AddSetting("prompt", Setting{
+		Help:  "shell prompt",
+		Value: func() string { return shell.DefaultPrompt },
+		Apply: func(c *shell.Interface, value string) error {
+			shell.DefaultPrompt = value
+
+			if c != nil {
+				c.Prompt = value
+			}
+
+			return nil
+		},
+	})
+
+	AddSetting("console", Setting{
+		Help:  "console (" + strings.Join(consoles, ", ") + "), applied at next boot",
+		Value: func() string { return Console },
+		Apply: func(_ *shell.Interface, value string) error {
+			if len(value) > 0 && !slices.Contains(consoles, strings.ToLower(value)) {
+				return fmt.Errorf("invalid console, expected one of %s", strings.Join(consoles, ", "))
+			}
+
+			Console = value
+			return nil
+		},
+	})
+}
+
+func readSettings() (buf []byte, err error) {
+	if _, buf, err = x64.UEFI.Runtime.GetVariable(SettingsVariable, VendorGUID, true); err == nil {
+		return
+	}
+
+	root, err := x64.UEFI.Root()
+
+	if err != nil {
+		return
+	}
+
+	return fs.ReadFile(root, strings.ReplaceAll(SettingsPath, `\`, `/`))
+}
+
+// saveSettings persists all set values and aliases.
+func saveSettings() (err error) {
+	var buf bytes.Buffer
+	var names []string
+
+	for name := range settings {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if s := settings[name]; s.value != nil {
+			fmt.Fprintf(&buf, "set %s %s\n", name, *s.value)
+		}
+	}
+
+	aliases := shell.Aliases()
+	names = nil
+
+	for name := range aliases {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&buf, "alias %s %s\n", name, aliases[name])
+	}
+
+	attr := uefi.VariableAttributes{
+		NonVolatile:       true,
+		BootServiceAccess: true,
+	}
+
+	if err = x64.UEFI.Runtime.SetVariable(SettingsVariable, VendorGUID, attr, buf.Bytes()); err == nil {
+		return
+	}
+
+	root, e := x64.UEFI.Root()
+
+	if e != nil {
+		return fmt.Errorf("could not set variable (%v) nor open root volume (%v)", err, e)
+	}
+
+	if e = root.WriteFile(SettingsPath, buf.Bytes()); e != nil {
+		return fmt.Errorf("could not set variable (%v) nor write %s (%v)", err, SettingsPath, e)
+	}
+
+	return nil
+}
+
+// LoadSettings applies persisted settings and aliases, it must be invoked
+// after compile time values are set and before starting the shell.
+func LoadSettings() (err error) {
+	for _, s := range settings {
+		s.defaultValue = s.Value()
+	}
+
+	buf, err := readSettings()
+
+	if err != nil {
+		// nothing persisted
+		return nil
+	}
+
+	for line := range strings.Lines(string(buf)) {
+		kind, rest, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		name, value, _ := strings.Cut(rest, " ")
+
+		switch kind {
+		case "set":
+			s, ok := settings[name]
+
+			if !ok {
+				continue
+			}
+
+			if e := s.Apply(nil, value); e != nil {
+				err = errors.Join(err, fmt.Errorf("invalid setting %s, %v", name, e))
+				continue
+			}
+
+			s.value = &value
+		case "alias":
+			shell.SetAlias(name, value)
+		}
+	}
+
+	return
+}
+
+func setCmd(c *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+	var names []string
+
+	if len(arg[0]) == 0 {
+		for name := range settings {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		t := tabwriter.NewWriter(&buf, 16, 8, 0, ' ', tabwriter.TabIndent)
+
+		for _, name := range names {
+			s := settings[name]
+			fmt.Fprintf(t, "%s\t%q\t # %s\n", name, s.Value(), s.Help)
+		}
+
+		t.Flush()
+
+		return buf.String(), nil
+	}
+
+	s, ok := settings[arg[0]]
+
+	if !ok {
+		return "", fmt.Errorf("unknown setting %s", arg[0])
+	}
+
+	value := arg[1]
+
+	if len(value) == 0 {
+		return fmt.Sprintf("%q\n", s.Value()), nil
+	}
+
+	if err = s.Apply(c, value); err != nil {
+		return
+	}
+
+	s.value = &value
+
+	return "", saveSettings()
+}
+
+func unsetCmd(c *shell.Interface, arg []string) (res string, err error) {
+	name := arg[1]
+
+	if len(arg[0]) > 0 {
+		if _, ok := shell.Aliases()[name]; !ok {
+			return "", fmt.Errorf("unknown alias %s", name)
+		}
+
+		shell.RemoveAlias(name)
+
+		return "", saveSettings()
+	}
+
+	s, ok := settings[name]
+
+	if !ok {
+		return "", fmt.Errorf("unknown setting %s", name)
+	}
+
+	if err = s.Apply(c, s.defaultValue); err != nil {
+		return
+	}
+
+	s.value = nil
+
+	return "", saveSettings()
+}
+
+func aliasCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+	var names []string
+
+	aliases := shell.Aliases()
+
+	switch {
+	case len(arg[0]) == 0:
+		for name := range aliases {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(&buf, "%s=%s\n", name, aliases[name])
+		}
+
+		return buf.String(), nil
+	case len(arg[1]) == 0:
+		value, ok := aliases[arg[0]]
+
+		if !ok {
+			return "", fmt.Errorf("unknown alias %s", arg[0])
+		}
+
+		return fmt.Sprintf("%s=%s\n", arg[0], value), nil
+	}
+
+	shell.SetAlias(arg[0], arg[1])
+
+	return "", saveSettings()
+}
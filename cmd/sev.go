@@ -10,7 +10,6 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
-	"regexp"
 
 	"github.com/usbarmory/tamago/dma"
 	"github.com/usbarmory/tamago/kvm/sev"
@@ -35,15 +34,17 @@ func init() {
 		Name: "sev",
 		Help: "AMD SEV-SNP information",
 		Fn:   sevCmd,
+		Data: sevData,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "sev-report",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^sev-report(?: (raw))?$`),
-		Syntax:  "(raw)?",
-		Help:    "AMD SEV-SNP attestation report",
-		Fn:      attestationCmd,
+		Name: "sev-report",
+		Spec: []shell.Arg{
+			{Name: "raw", Kind: shell.Flag, Help: "show raw report"},
+		},
+		Help: "AMD SEV-SNP attestation report",
+		Fn:   attestationCmd,
+		Data: attestationData,
 	})
 
 	shell.Add(shell.Cmd{
@@ -53,6 +54,94 @@ func init() {
 	})
 }
 
+// SEVInfo represents the `sev` command structured output.
+type SEVInfo struct {
+	SEV          bool        `json:"sev"`
+	ES           bool        `json:"es"`
+	SNP          bool        `json:"snp"`
+	EncryptedBit int         `json:"encrypted_bit"`
+	SNPVersion   uint16      `json:"snp_version,omitempty"`
+	Secrets      *SEVSecrets `json:"secrets,omitempty"`
+}
+
+// SEVSecrets represents the `sev` command secrets page information.
+type SEVSecrets struct {
+	Address         uint64 `json:"address"`
+	Size            uint32 `json:"size"`
+	Version         uint32 `json:"version"`
+	TSCFactor       uint32 `json:"tsc_factor"`
+	LaunchMitVector uint64 `json:"launch_mitigations"`
+}
+
+// AttestationReport represents the `sev-report` command structured output.
+type AttestationReport struct {
+	Version          uint32 `json:"version"`
+	VMPL             uint32 `json:"vmpl"`
+	SignatureAlgo    uint32 `json:"signature_algo"`
+	CurrentTCB       uint64 `json:"current_tcb"`
+	Measurement      string `json:"measurement"`
+	ReportedTCB      uint64 `json:"reported_tcb"`
+	CommittedTCB     uint64 `json:"committed_tcb"`
+	LaunchMitVector  uint64 `json:"launch_mitigations"`
+	CurrentMitVector uint64 `json:"current_mitigations"`
+	SignatureR       string `json:"signature_r"`
+	SignatureS       string `json:"signature_s"`
+	Raw              string `json:"raw,omitempty"`
+}
+
+func initSecrets(snp *uefi.SNPConfigurationTable) (err error) {
+	if secrets != nil {
+		return
+	}
+
+	secrets = &sev.SecretsPage{}
+
+	if err = secrets.Init(uint(snp.SecretsPagePhysicalAddress), int(snp.SecretsPageSize)); err != nil {
+		secrets = nil
+	}
+
+	return
+}
+
+func sevData(_ *shell.Interface, _ []string) (any, error) {
+	if features == nil {
+		features = sev.Features(x64.AMD64)
+	}
+
+	info := &SEVInfo{
+		SEV:          features.SEV.SEV,
+		ES:           features.SEV.ES,
+		SNP:          features.SEV.SNP,
+		EncryptedBit: features.EncryptedBit,
+	}
+
+	if !features.SEV.SNP {
+		return info, nil
+	}
+
+	snp, err := x64.UEFI.GetSNPConfiguration()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not find AMD SEV-SNP pages, %v", err)
+	}
+
+	info.SNPVersion = snp.Version
+
+	if err = initSecrets(snp); err != nil {
+		return nil, fmt.Errorf("could not initialize AMD SEV-SNP secrets, %v", err)
+	}
+
+	info.Secrets = &SEVSecrets{
+		Address:         snp.SecretsPagePhysicalAddress,
+		Size:            snp.SecretsPageSize,
+		Version:         secrets.Version,
+		TSCFactor:       secrets.TSCFactor,
+		LaunchMitVector: secrets.LaunchMitVector,
+	}
+
+	return info, nil
+}
+
 func sevCmd(_ *shell.Interface, _ []string) (res string, err error) {
 	var buf bytes.Buffer
 	var snp *uefi.SNPConfigurationTable
@@ -83,13 +172,9 @@ func sevCmd(_ *shell.Interface, _ []string) (res string, err error) {
 	fmt.Fprintf(&buf, "SNP Version ........: %d\n\n", snp.Version)
 	fmt.Fprintf(&buf, "Secrets Page .......: %#x (%d bytes)\n", snp.SecretsPagePhysicalAddress, snp.SecretsPageSize)
 
-	if secrets == nil {
-		secrets = &sev.SecretsPage{}
-
-		if err = secrets.Init(uint(snp.SecretsPagePhysicalAddress), int(snp.SecretsPageSize)); err != nil {
-			fmt.Fprintf(&buf, " could not initialize AMD SEV-SNP secrets, %v", err)
-			return
-		}
+	if err = initSecrets(snp); err != nil {
+		fmt.Fprintf(&buf, " could not initialize AMD SEV-SNP secrets, %v", err)
+		return
 	}
 
 	fmt.Fprintf(&buf, "Secrets Version ....: %d\n", secrets.Version)
@@ -152,19 +237,56 @@ func initGHCB() (err error) {
 	return
 }
 
-func attestationCmd(_ *shell.Interface, arg []string) (res string, err error) {
-	var buf bytes.Buffer
-	var report *sev.AttestationReport
-
+func attestationReport() (report *sev.AttestationReport, err error) {
 	if err = initGHCB(); err != nil {
-		return "", fmt.Errorf("could not initialize GHCB, %v", err)
+		return nil, fmt.Errorf("could not initialize GHCB, %v", err)
 	}
 
 	data := make([]byte, 64)
 	rand.Read(data)
 
 	if report, err = ghcb.GetAttestationReport(data, secrets.VMPCK0[:], 0); err != nil {
-		return "", fmt.Errorf("could not get report, %v", err)
+		return nil, fmt.Errorf("could not get report, %v", err)
+	}
+
+	return
+}
+
+func attestationData(_ *shell.Interface, arg []string) (any, error) {
+	report, err := attestationReport()
+
+	if err != nil {
+		return nil, err
+	}
+
+	r := &AttestationReport{
+		Version:          report.Version,
+		VMPL:             report.VMPL,
+		SignatureAlgo:    report.SignatureAlgo,
+		CurrentTCB:       report.CurrentTCB,
+		Measurement:      fmt.Sprintf("%x", report.Measurement),
+		ReportedTCB:      report.ReportedTCB,
+		CommittedTCB:     report.CommittedTCB,
+		LaunchMitVector:  report.LaunchMitVector,
+		CurrentMitVector: report.CurrentMitVector,
+		SignatureR:       fmt.Sprintf("%x", report.Signature[0:48]),
+		SignatureS:       fmt.Sprintf("%x", report.Signature[72:72+48]),
+	}
+
+	if len(arg[0]) > 0 {
+		r.Raw = fmt.Sprintf("%x", report.Bytes())
+	}
+
+	return r, nil
+}
+
+func attestationCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+
+	report, err := attestationReport()
+
+	if err != nil {
+		return
 	}
 
 	if len(arg[0]) > 0 {
@@ -0,0 +1,112 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/smbios"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "sysinfo",
+		Help: "SMBIOS system information",
+		Fn:   sysinfoCmd,
+	})
+}
+
+// SystemInformation returns the SMBIOS structure table, located through the
+// EFI Configuration Tables.
+func SystemInformation() (t *smbios.SMBIOS, err error) {
+	var c *uefi.ConfigurationTable
+
+	s := x64.UEFI.SystemTable
+
+	if c, err = s.LocateConfiguration(uefi.SMBIOS3_TABLE_GUID); err != nil {
+		if c, err = s.LocateConfiguration(uefi.SMBIOS_TABLE_GUID); err != nil {
+			return nil, fmt.Errorf("could not find SMBIOS entry point, %v", err)
+		}
+	}
+
+	return smbios.Parse(readMemory, uint(c.VendorTable))
+}
+
+func formatSize(n uint64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%d GB", n>>30)
+	case n >= 1<<20:
+		return fmt.Sprintf("%d MB", n>>20)
+	default:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+}
+
+func sysinfoCmd(_ *shell.Interface, _ []string) (res string, err error) {
+	var buf bytes.Buffer
+
+	t, err := SystemInformation()
+
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(&buf, "SMBIOS Version .....: %s (%d structures)\n", t.EntryPoint.Version(), len(t.Structures))
+
+	if b := t.BIOS; b != nil {
+		fmt.Fprintf(&buf, "BIOS Vendor ........: %s\n", b.Vendor)
+		fmt.Fprintf(&buf, "BIOS Version .......: %s (%s)\n", b.Version, b.ReleaseDate)
+		fmt.Fprintf(&buf, "BIOS ROM Size ......: %s\n", formatSize(b.ROMSize))
+	}
+
+	if s := t.System; s != nil {
+		fmt.Fprintf(&buf, "Manufacturer .......: %s\n", s.Manufacturer)
+		fmt.Fprintf(&buf, "Product Name .......: %s\n", s.ProductName)
+		fmt.Fprintf(&buf, "Version ............: %s\n", s.Version)
+		fmt.Fprintf(&buf, "Serial Number ......: %s\n", s.SerialNumber)
+		fmt.Fprintf(&buf, "SKU Number .........: %s\n", s.SKUNumber)
+		fmt.Fprintf(&buf, "Family .............: %s\n", s.Family)
+		fmt.Fprintf(&buf, "UUID ...............: %s\n", s.UUID)
+	}
+
+	for _, b := range t.Baseboards {
+		fmt.Fprintf(&buf, "Baseboard ..........: %s %s %s (serial:%s)\n", b.Manufacturer, b.Product, b.Version, b.SerialNumber)
+	}
+
+	for _, c := range t.Chassis {
+		fmt.Fprintf(&buf, "Chassis ............: %s %s (serial:%s asset:%s)\n", c.Manufacturer, c.TypeName(), c.SerialNumber, c.AssetTag)
+	}
+
+	for _, p := range t.Processors {
+		if !p.Populated() {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "Processor ..........: %s %s\n", p.SocketDesignation, p.Version)
+		fmt.Fprintf(&buf, "                      cores:%d threads:%d speed:%d/%d MHz id:%016x\n",
+			p.CoreCount, p.ThreadCount, p.CurrentSpeed, p.MaxSpeed, p.ID)
+	}
+
+	for _, m := range t.MemoryDevices {
+		if m.Size == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "Memory Device ......: %s %s %s %s %d MT/s %s %s\n",
+			m.DeviceLocator, m.BankLocator, formatSize(m.Size), m.TypeName(), m.Speed, m.Manufacturer, m.PartNumber)
+	}
+
+	for i, s := range t.OEMStrings {
+		fmt.Fprintf(&buf, "OEM String %-2d ......: %s\n", i+1, s)
+	}
+
+	return buf.String(), nil
+}
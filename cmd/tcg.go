@@ -0,0 +1,143 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/usbarmory/armory-boot/exec"
+
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+// PCR indices for measured boot, following systemd-stub and GRUB conventions.
+const (
+	// PCR 4: boot loader code and boot attempts
+	KernelPCR = 4
+	// PCR 8: kernel command line and boot loader commands
+	CmdLinePCR = 8
+	// PCR 9: files loaded by the boot loader
+	InitrdPCR = 9
+)
+
+func tcg2() (tcg *uefi.TCG2, err error) {
+	var c *uefi.TCG2Capability
+
+	if tcg, err = x64.UEFI.Boot.GetTCG2(); err != nil {
+		return
+	}
+
+	if c, err = tcg.GetCapability(); err != nil {
+		return nil, err
+	}
+
+	if c.TPMPresentFlag == 0 {
+		return nil, errors.New("TPM not present")
+	}
+
+	return
+}
+
+func measure(tcg *uefi.TCG2, pcr uint32, eventType uint32, flags uint64, data []byte, desc []byte) (err error) {
+	event := &uefi.TCG2Event{
+		Header: uefi.TCG2EventHeader{
+			PCRIndex:  pcr,
+			EventType: eventType,
+		},
+		Event: desc,
+	}
+
+	if err = tcg.HashLogExtendEvent(flags, data, event); err != nil {
+		return fmt.Errorf("could not measure event (PCR %d), %v", pcr, err)
+	}
+
+	return
+}
+
+// peImage returns whether the argument image is in PE/COFF format.
+func peImage(buf []byte) bool {
+	if len(buf) < 0x40 || string(buf[0:2]) != "MZ" {
+		return false
+	}
+
+	// e_lfanew
+	off := int(binary.LittleEndian.Uint32(buf[0x3c:]))
+
+	return off >= 0x40 && off+4 <= len(buf) && string(buf[off:off+4]) == "PE\x00\x00"
+}
+
+// measureLinux measures the argument kernel, initrd and command line before
+// execution, in absence of a TPM the measurement is skipped.
+func measureLinux(image *exec.LinuxImage) (err error) {
+	tcg, err := tcg2()
+
+	if err != nil {
+		log.Printf("skipping measurements, %v", err)
+		return nil
+	}
+
+	event := uefi.NewImageLoadEvent(image.Kernel)
+
+	// The kernel is measured as if it was loaded through LoadImage() by
+	// firmware (Authenticode digest) when it is a PE/COFF image (EFI stub),
+	// otherwise as a flat image. The mode only depends on the image format,
+	// keeping PCR 4 predictable.
+	if peImage(image.Kernel) {
+		log.Printf("measuring kernel as PE/COFF image")
+		err = measure(tcg, KernelPCR, uefi.EV_EFI_BOOT_SERVICES_APPLICATION, uefi.PE_COFF_IMAGE, image.Kernel, event.Bytes())
+	} else {
+		log.Printf("measuring kernel as flat image")
+		err = measure(tcg, KernelPCR, uefi.EV_EFI_BOOT_SERVICES_APPLICATION, 0, image.Kernel, event.Bytes())
+	}
+
+	if err != nil {
+		return
+	}
+
+	if len(image.InitialRamDisk) > 0 {
+		if err = measure(tcg, InitrdPCR, uefi.EV_IPL, 0, image.InitialRamDisk, []byte("Linux initrd")); err != nil {
+			return
+		}
+	}
+
+	if len(image.CmdLine) > 0 {
+		if err = measure(tcg, CmdLinePCR, uefi.EV_IPL, 0, []byte(image.CmdLine), []byte(image.CmdLine)); err != nil {
+			return
+		}
+	}
+
+	log.Printf("measured kernel (PCR %d), initrd (PCR %d), command line (PCR %d)", KernelPCR, InitrdPCR, CmdLinePCR)
+
+	return
+}
+
+// measureImage measures the command launching the argument EFI image, in
+// absence of a TPM the measurement is skipped.
+//
+// The EFI image itself is not measured as firmware is required to measure
+// images started with LoadImage() in PCR 4.
+func measureImage(path string) (err error) {
+	tcg, err := tcg2()
+
+	if err != nil {
+		log.Printf("skipping measurements, %v", err)
+		return nil
+	}
+
+	cmd := []byte(fmt.Sprintf(". %s", path))
+
+	if err = measure(tcg, CmdLinePCR, uefi.EV_IPL, 0, cmd, cmd); err != nil {
+		return
+	}
+
+	log.Printf("measured command line (PCR %d)", CmdLinePCR)
+
+	return
+}
@@ -0,0 +1,369 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/usbarmory/go-boot/shell"
+	"github.com/usbarmory/go-boot/tpm"
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/uefi/x64"
+)
+
+const (
+	// sealed object file extension
+	sealedExt = ".sealed"
+	// initrd directory for unsealed secrets
+	credentialsPath = "/.extra/go-boot"
+	// TCG_PCClientPCREvent size without event data
+	eventLogHeaderSize = 32
+)
+
+// DefaultSealPCRs represents the default PCR selection for `tpm seal`.
+var DefaultSealPCRs = []int{0, 2, 4, 7}
+
+// unsealed secrets, passed to Linux within its initrd
+var credentials = make(map[string][]byte)
+
+func init() {
+	shell.Add(shell.Cmd{
+		Name: "tpm pcrs",
+		Spec: []shell.Arg{
+			{Name: "algorithm", Kind: shell.Enum, Values: hashAlgorithms, Optional: true},
+		},
+		Help: "show TPM PCR values",
+		Fn:   pcrsCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "tpm seal",
+		Spec: []shell.Arg{
+			{Name: "path", Help: "file to seal"},
+			{Name: "pcr,...", Optional: true, Help: "comma separated PCR indices"},
+		},
+		Help: "seal file to TPM PCRs",
+		Fn:   sealCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "tpm unseal",
+		Spec: []shell.Arg{
+			{Name: "path", Help: "sealed file"},
+		},
+		Help: "unseal file for Linux initrd",
+		Fn:   unsealCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "eventlog",
+		Spec: []shell.Arg{
+			{Name: "algorithm", Kind: shell.Enum, Values: hashAlgorithms, Optional: true},
+			{Name: "replay", Kind: shell.Flag, Help: "replay events and compare with PCRs"},
+		},
+		Help: "show TCG event log and replay PCRs",
+		Fn:   eventLogCmd,
+	})
+}
+
+// supported PCR bank algorithms
+var hashAlgorithms = []string{"sha1", "sha256", "sha384"}
+
+func parseAlg(name string) (alg uint16, s string) {
+	switch name {
+	case "sha1":
+		return tpm.TPM_ALG_SHA1, name
+	case "sha384":
+		return tpm.TPM_ALG_SHA384, name
+	default:
+		return tpm.TPM_ALG_SHA256, "sha256"
+	}
+}
+
+func getTPM() (t *tpm.TPM, err error) {
+	tcg, err := tcg2()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not find TPM, %v", err)
+	}
+
+	return &tpm.TPM{Transport: tcg}, nil
+}
+
+func pcrsCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+	var values map[int][]byte
+	var pcrs []int
+
+	alg, _ := parseAlg(arg[0])
+	t, err := getTPM()
+
+	if err != nil {
+		return
+	}
+
+	for i := range tpm.NumPCR {
+		pcrs = append(pcrs, i)
+	}
+
+	if values, err = t.PCRRead(alg, pcrs); err != nil {
+		return "", fmt.Errorf("could not read PCRs, %v", err)
+	}
+
+	for _, i := range pcrs {
+		fmt.Fprintf(&buf, "%2d: %x\n", i, values[i])
+	}
+
+	return buf.String(), nil
+}
+
+func sealCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var obj *tpm.SealedObject
+
+	pcrs := DefaultSealPCRs
+
+	if len(arg[1]) > 0 {
+		pcrs = nil
+
+		for _, s := range strings.Split(arg[1], ",") {
+			pcr, err := strconv.Atoi(s)
+
+			if err != nil {
+				return "", fmt.Errorf("invalid PCR, %v", err)
+			}
+
+			pcrs = append(pcrs, pcr)
+		}
+	}
+
+	root, err := x64.UEFI.Root()
+
+	if err != nil {
+		return "", fmt.Errorf("could not open root volume, %v", err)
+	}
+
+	name := strings.ReplaceAll(arg[0], `\`, `/`)
+	data, err := fs.ReadFile(root, name)
+
+	if err != nil {
+		return "", fmt.Errorf("could not read file, %v", err)
+	}
+
+	t, err := getTPM()
+
+	if err != nil {
+		return
+	}
+
+	if obj, err = t.SealPCR(data, pcrs); err != nil {
+		return "", fmt.Errorf("could not seal, %v", err)
+	}
+
+	if err = root.WriteFile(name+sealedExt, obj.Bytes()); err != nil {
+		return "", fmt.Errorf("could not write file, %v", err)
+	}
+
+	return fmt.Sprintf("sealed to PCRs %v: %s\n", pcrs, arg[0]+sealedExt), nil
+}
+
+func unsealCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var obj *tpm.SealedObject
+	var data []byte
+
+	root, err := x64.UEFI.Root()
+
+	if err != nil {
+		return "", fmt.Errorf("could not open root volume, %v", err)
+	}
+
+	name := strings.ReplaceAll(arg[0], `\`, `/`)
+	buf, err := fs.ReadFile(root, name)
+
+	if err != nil {
+		return "", fmt.Errorf("could not read file, %v", err)
+	}
+
+	if obj, err = tpm.ParseSealedObject(buf); err != nil {
+		return
+	}
+
+	t, err := getTPM()
+
+	if err != nil {
+		return
+	}
+
+	if data, err = t.UnsealPCR(obj); err != nil {
+		return "", fmt.Errorf("could not unseal, %v", err)
+	}
+
+	cred := path.Join(credentialsPath, strings.TrimSuffix(path.Base(name), sealedExt))
+	credentials[cred] = data
+
+	log.Printf("unsealed %s (%d bytes) as initrd %s", arg[0], len(data), cred)
+
+	return
+}
+
+func readEventLog() (l *tpm.EventLog, truncated bool, err error) {
+	var log *uefi.EventLog
+
+	tcg, err := tcg2()
+
+	if err != nil {
+		return nil, false, fmt.Errorf("could not find TPM, %v", err)
+	}
+
+	if log, err = tcg.GetEventLog(uefi.EFI_TCG2_EVENT_LOG_FORMAT_TCG_2); err != nil {
+		return nil, false, fmt.Errorf("could not get event log, %v", err)
+	}
+
+	if log.Location == 0 {
+		return nil, false, errors.New("event log unavailable")
+	}
+
+	// read Spec ID event header
+	hdr := memCopy(uint(log.Location), eventLogHeaderSize, nil)
+	size := eventLogHeaderSize + int(binary.LittleEndian.Uint32(hdr[eventLogHeaderSize-4:]))
+
+	if l, err = tpm.ParseEventLog(memCopy(uint(log.Location), size, nil)); err != nil {
+		return
+	}
+
+	if log.LastEntry <= log.Location {
+		return l, log.Truncated, nil
+	}
+
+	// read last entry header to find out the log size
+	hdr = memCopy(uint(log.LastEntry), l.MaxEventHeaderSize(), nil)
+
+	if size, err = l.EventSize(hdr); err != nil {
+		return
+	}
+
+	size += int(log.LastEntry - log.Location)
+	l, err = tpm.ParseEventLog(memCopy(uint(log.Location), size, nil))
+
+	return l, log.Truncated, err
+}
+
+func eventLogCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+	var values map[int][]byte
+	var pcrs []int
+
+	alg, name := parseAlg(arg[0])
+	l, truncated, err := readEventLog()
+
+	if err != nil {
+		return
+	}
+
+	if len(arg[1]) == 0 {
+		for i, e := range l.Events {
+			fmt.Fprintf(&buf, "%3d PCR%-2d %s %s\n", i, e.PCRIndex, e.Type(), e)
+
+			if d := e.Digest(alg); d != nil {
+				fmt.Fprintf(&buf, "    %s:%x\n", name, d)
+			}
+		}
+
+		if truncated {
+			fmt.Fprintf(&buf, "WARNING: event log is truncated\n")
+		}
+
+		fmt.Fprintf(&buf, "\n")
+	}
+
+	replay, ok := l.Replay()[alg]
+
+	if !ok {
+		return "", fmt.Errorf("%s bank not present in event log", name)
+	}
+
+	for pcr := range replay {
+		pcrs = append(pcrs, pcr)
+	}
+
+	sort.Ints(pcrs)
+
+	if t, err := getTPM(); err == nil {
+		values, _ = t.PCRRead(alg, pcrs)
+	}
+
+	fmt.Fprintf(&buf, "PCR %s (replayed)\n", name)
+
+	for _, pcr := range pcrs {
+		status := ""
+
+		switch {
+		case values == nil:
+		case bytes.Equal(values[pcr], replay[pcr]):
+			status = "ok"
+		default:
+			status = fmt.Sprintf("MISMATCH (TPM %x)", values[pcr])
+		}
+
+		fmt.Fprintf(&buf, "%2d: %x %s\n", pcr, replay[pcr], status)
+	}
+
+	return buf.String(), nil
+}
+
+// cpioEntry returns a cpio "newc" format archive entry.
+func cpioEntry(name string, mode uint32, data []byte) (buf []byte) {
+	hdr := fmt.Sprintf("070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+		0, mode, 0, 0, 1, 0, len(data), 0, 0, 0, 0, len(name)+1, 0)
+
+	buf = append([]byte(hdr), name...)
+	buf = append(buf, 0x00)
+	buf = append(buf, make([]byte, -len(buf)&3)...)
+	buf = append(buf, data...)
+	buf = append(buf, make([]byte, -len(data)&3)...)
+
+	return
+}
+
+// appendCredentials appends unsealed secrets, if any, to the argument initrd
+// as an additional cpio archive.
+func appendCredentials(initrd []byte) []byte {
+	var names []string
+
+	if len(credentials) == 0 {
+		return initrd
+	}
+
+	for name := range credentials {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	// concatenated archives must be 4-byte aligned
+	initrd = append(initrd, make([]byte, -len(initrd)&3)...)
+
+	dir := ""
+
+	for _, d := range strings.Split(strings.TrimPrefix(credentialsPath, "/"), "/") {
+		dir = path.Join(dir, d)
+		initrd = append(initrd, cpioEntry(dir, 0040500, nil)...)
+	}
+
+	for _, name := range names {
+		initrd = append(initrd, cpioEntry(strings.TrimPrefix(name, "/"), 0100400, credentials[name])...)
+	}
+
+	return append(initrd, cpioEntry("TRAILER!!!", 0, nil)...)
+}
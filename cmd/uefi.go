@@ -7,13 +7,14 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
-	"regexp"
 	"strconv"
 	"strings"
 	"unicode/utf16"
@@ -34,61 +35,56 @@ func init() {
 		Name: "uefi",
 		Help: "UEFI information",
 		Fn:   uefiCmd,
+		Data: uefiData,
 	})
 
-	shell.Add(shell.Cmd{
-		Name:    ". ",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^\.(?: (\S+))?$`),
-		Syntax:  "<path>",
-		Help:    "load and start EFI image",
-		Fn:      imageCmd,
-	})
+	addImageCmd()
 
-	if len(DefaultEFIEntry) > 0 {
-		shell.Add(shell.Cmd{
-			Name:    ".",
-			Args:    1,
-			Pattern: regexp.MustCompile(`^\.(?: (\S+))?$`),
-			Help:    fmt.Sprintf("`. %s`", DefaultEFIEntry),
-			Fn:      imageCmd,
-		})
-	}
+	AddSetting("efi", Setting{
+		Help:  "default EFI image",
+		Value: func() string { return DefaultEFIEntry },
+		Apply: func(_ *shell.Interface, value string) error {
+			DefaultEFIEntry = value
+			addImageCmd()
+			return nil
+		},
+	})
 
 	shell.Add(shell.Cmd{
-		Name:    "protocol",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^protocol ([[:xdigit:]]{8}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{12})$`),
-		Syntax:  "<registry format GUID>",
-		Help:    "locate UEFI protocol",
-		Fn:      locateCmd,
+		Name: "protocol",
+		Spec: []shell.Arg{
+			{Name: "guid", Help: "registry format GUID"},
+		},
+		Help: "locate UEFI protocol",
+		Fn:   locateCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "cat",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^cat (.*)`),
-		Syntax:  "<path>",
-		Help:    "show file contents",
-		Fn:      catCmd,
+		Name: "cat",
+		Spec: []shell.Arg{
+			{Name: "path", Kind: shell.Text},
+		},
+		Help:   "show file contents",
+		Stream: catCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "ls",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^ls(?: (\S+))?$`),
-		Syntax:  "(<path>)?",
-		Help:    "list directory contents",
-		Fn:      lsCmd,
+		Name: "ls",
+		Spec: []shell.Arg{
+			{Name: "path", Optional: true},
+		},
+		Help: "list directory contents",
+		Fn:   lsCmd,
+		Data: lsData,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "stat",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^stat (.*)`),
-		Syntax:  "<path>",
-		Help:    "show file information",
-		Fn:      statCmd,
+		Name: "stat",
+		Spec: []shell.Arg{
+			{Name: "path", Kind: shell.Text},
+		},
+		Help: "show file information",
+		Fn:   statCmd,
 	})
 
 	shell.Add(shell.Cmd{
@@ -98,56 +94,111 @@ func init() {
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "mode",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^mode (\d+)$`),
-		Syntax:  "<mode>",
-		Help:    "set screen mode",
-		Fn:      modeCmd,
+		Name: "mode",
+		Spec: []shell.Arg{
+			{Name: "mode", Kind: shell.Uint, Help: "text mode number"},
+		},
+		Help: "set screen mode",
+		Fn:   modeCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "memmap",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^memmap( e820)?$`),
-		Help:    "show UEFI memory map",
-		Syntax:  "(e820)?",
-		Fn:      memmapCmd,
+		Name: "gmode",
+		Spec: []shell.Arg{
+			{Name: "mode", Kind: shell.Uint, Optional: true, Help: "graphics mode number"},
+		},
+		Help: "list or set graphics mode",
+		Fn:   gmodeCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "reset",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^reset(?: (cold|warm))?$`),
-		Help:    "reset system",
-		Syntax:  "(cold|warm)?",
-		Fn:      resetCmd,
+		Name: "memmap",
+		Spec: []shell.Arg{
+			{Name: "e820", Kind: shell.Flag, Help: "show E820 format"},
+		},
+		Help: "show UEFI memory map",
+		Fn:   memmapCmd,
+		Data: memmapData,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "halt,shutdown",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^(halt|shutdown)$`),
-		Help:    "shutdown system",
-		Fn:      shutdownCmd,
+		Name: "reset",
+		Spec: []shell.Arg{
+			{Name: "type", Kind: shell.Enum, Values: []string{"cold", "warm"}, Optional: true, Default: "warm"},
+		},
+		Help: "reset system",
+		Fn:   resetCmd,
 	})
 
 	shell.Add(shell.Cmd{
-		Name:    "efivar",
-		Args:    1,
-		Pattern: regexp.MustCompile(`^efivar(?: (verbose))?$`),
-		Syntax:  "(verbose)?",
-		Help:    "list all UEFI variables",
-		Fn:      efivarCmd,
+		Name: "halt,shutdown",
+		Help: "shutdown system",
+		Fn:   shutdownCmd,
+	})
+
+	shell.Add(shell.Cmd{
+		Name: "efivar",
+		Spec: []shell.Arg{
+			{Name: "verbose", Kind: shell.Flag, Help: "show variable contents"},
+		},
+		Help: "list all UEFI variables",
+		Fn:   efivarCmd,
+		Data: efivarData,
 	})
 }
 
-func uefiCmd(_ *shell.Interface, _ []string) (res string, err error) {
-	var buf bytes.Buffer
+// UEFIInfo represents the `uefi` command structured output.
+type UEFIInfo struct {
+	Revision            string         `json:"revision"`
+	FirmwareVendor      string         `json:"firmware_vendor"`
+	FirmwareRevision    uint32         `json:"firmware_revision"`
+	RuntimeServices     uint64         `json:"runtime_services"`
+	BootServices        uint64         `json:"boot_services"`
+	FrameBuffer         *FrameBuffer   `json:"frame_buffer,omitempty"`
+	ConfigurationTable  uint64         `json:"configuration_table"`
+	ConfigurationTables []*VendorTable `json:"configuration_tables"`
+}
+
+// VendorTable represents the `uefi` command configuration table entries.
+type VendorTable struct {
+	GUID    string `json:"guid"`
+	Address uint64 `json:"address"`
+}
+
+// FrameBuffer represents the `uefi` command frame buffer information.
+type FrameBuffer struct {
+	Width  uint16 `json:"width"`
+	Height uint16 `json:"height"`
+	Base   uint64 `json:"base"`
+}
+
+// File represents a `ls` command structured output entry.
+type File struct {
+	Name string `json:"name"`
+	Dir  bool   `json:"dir"`
+	Size int64  `json:"size"`
+}
+
+// MemoryRegion represents a `memmap` command structured output entry.
+type MemoryRegion struct {
+	Type      uint32 `json:"type"`
+	Start     uint64 `json:"start"`
+	End       uint64 `json:"end"`
+	Pages     uint64 `json:"pages"`
+	Attribute uint64 `json:"attribute,omitempty"`
+}
+
+// Variable represents an `efivar` command structured output entry.
+type Variable struct {
+	GUID       string                   `json:"guid"`
+	Name       string                   `json:"name"`
+	Attributes *uefi.VariableAttributes `json:"attributes,omitempty"`
+}
+
+func firmwareVendor() string {
 	var s []uint16
 
-	t := x64.UEFI.SystemTable
-	b := memCopy(uint(t.FirmwareVendor), maxVendorSize, nil)
+	b := memCopy(uint(x64.UEFI.SystemTable.FirmwareVendor), maxVendorSize, nil)
 
 	for i := 0; i < maxVendorSize; i += 2 {
 		if b[i] == 0x00 && b[i+1] == 0 {
@@ -157,26 +208,80 @@ func uefiCmd(_ *shell.Interface, _ []string) (res string, err error) {
 		s = append(s, binary.LittleEndian.Uint16(b[i:i+2]))
 	}
 
-	fmt.Fprintf(&buf, "UEFI Revision ......: %s\n", t.Revision())
-	fmt.Fprintf(&buf, "Firmware Vendor ....: %s\n", string(utf16.Decode(s)))
-	fmt.Fprintf(&buf, "Firmware Revision ..: %#x\n", t.FirmwareRevision)
-	fmt.Fprintf(&buf, "Runtime Services  ..: %#x\n", t.RuntimeServices)
-	fmt.Fprintf(&buf, "Boot Services ......: %#x\n", t.BootServices)
+	return string(utf16.Decode(s))
+}
 
-	if s, err := screenInfo(); err == nil {
-		fmt.Fprintf(&buf, "Frame Buffer .......: %dx%d @ %#x\n",
-			s.LfbWidth, s.LfbHeight,
-			uint64(s.ExtLfbBase)<<32|uint64(s.LfbBase))
+func addImageCmd() {
+	shell.Add(shell.Cmd{
+		Name: ".",
+		Spec: []shell.Arg{
+			{Name: "path", Optional: len(DefaultEFIEntry) > 0, Default: DefaultEFIEntry, Help: "EFI image path"},
+		},
+		Help: "load and start EFI image",
+		Fn:   imageCmd,
+	})
+}
+
+func uefiData(_ *shell.Interface, _ []string) (any, error) {
+	t := x64.UEFI.SystemTable
+
+	info := &UEFIInfo{
+		Revision:            t.Revision(),
+		FirmwareVendor:      firmwareVendor(),
+		FirmwareRevision:    t.FirmwareRevision,
+		RuntimeServices:     t.RuntimeServices,
+		BootServices:        t.BootServices,
+		ConfigurationTable:  t.ConfigurationTable,
+		ConfigurationTables: []*VendorTable{},
 	}
 
-	fmt.Fprintf(&buf, "Configuration Tables: %#x\n", t.ConfigurationTable)
+	if s, err := screenInfo(); err == nil {
+		info.FrameBuffer = &FrameBuffer{
+			Width:  s.LfbWidth,
+			Height: s.LfbHeight,
+			Base:   uint64(s.ExtLfbBase)<<32 | uint64(s.LfbBase),
+		}
+	}
 
 	if c, err := t.ConfigurationTables(); err == nil {
 		for _, t := range c {
-			fmt.Fprintf(&buf, "  %s (%#x)\n", t.GUID.String(), t.VendorTable)
+			info.ConfigurationTables = append(info.ConfigurationTables, &VendorTable{
+				GUID:    t.GUID.String(),
+				Address: t.VendorTable,
+			})
 		}
 	}
 
+	return info, nil
+}
+
+func uefiCmd(c *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+
+	data, err := uefiData(c, arg)
+
+	if err != nil {
+		return
+	}
+
+	info := data.(*UEFIInfo)
+
+	fmt.Fprintf(&buf, "UEFI Revision ......: %s\n", info.Revision)
+	fmt.Fprintf(&buf, "Firmware Vendor ....: %s\n", info.FirmwareVendor)
+	fmt.Fprintf(&buf, "Firmware Revision ..: %#x\n", info.FirmwareRevision)
+	fmt.Fprintf(&buf, "Runtime Services  ..: %#x\n", info.RuntimeServices)
+	fmt.Fprintf(&buf, "Boot Services ......: %#x\n", info.BootServices)
+
+	if fb := info.FrameBuffer; fb != nil {
+		fmt.Fprintf(&buf, "Frame Buffer .......: %dx%d @ %#x\n", fb.Width, fb.Height, fb.Base)
+	}
+
+	fmt.Fprintf(&buf, "Configuration Tables: %#x\n", info.ConfigurationTable)
+
+	for _, t := range info.ConfigurationTables {
+		fmt.Fprintf(&buf, "  %s (%#x)\n", t.GUID, t.Address)
+	}
+
 	return buf.String(), err
 }
 
@@ -187,12 +292,24 @@ func imageCmd(_ *shell.Interface, arg []string) (res string, err error) {
 		path = DefaultEFIEntry
 	}
 
+	if err = approved(path); err != nil {
+		return
+	}
+
+	if err = verifiedImage(); err != nil {
+		return
+	}
+
 	root, err := x64.UEFI.Root()
 
 	if err != nil {
 		return "", fmt.Errorf("could not open root volume, %v", err)
 	}
 
+	if err = measureImage(path); err != nil {
+		return "", fmt.Errorf("could not measure image, %v", err)
+	}
+
 	log.Printf("loading EFI image %s", path)
 	h, err := x64.UEFI.Boot.LoadImage(0, root, path)
 
@@ -213,28 +330,48 @@ func locateCmd(_ *shell.Interface, arg []string) (res string, err error) {
 	return fmt.Sprintf("%s: %#08x\n", arg[0], addr), err
 }
 
-func catCmd(_ *shell.Interface, arg []string) (res string, err error) {
+func catCmd(ctx context.Context, _ *shell.Interface, w io.Writer, arg []string) (err error) {
 	root, err := x64.UEFI.Root()
 
 	if err != nil {
-		return "", fmt.Errorf("could not open root volume, %v", err)
+		return fmt.Errorf("could not open root volume, %v", err)
 	}
 
-	arg[0] = strings.ReplaceAll(arg[0], `\`, `/`)
-	buf, err := fs.ReadFile(root, arg[0])
+	f, err := root.Open(strings.ReplaceAll(arg[0], `\`, `/`))
 
 	if err != nil {
-		return "", fmt.Errorf("could not read file, %v", err)
+		return fmt.Errorf("could not read file, %v", err)
 	}
+	defer f.Close()
 
-	return string(buf), nil
+	buf := make([]byte, 4096)
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		n, err := f.Read(buf)
+
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return fmt.Errorf("could not read file, %v", err)
+		}
+	}
 }
 
-func lsCmd(_ *shell.Interface, arg []string) (res string, err error) {
-	var buf bytes.Buffer
+func listDir(arg string) (files []*File, err error) {
 	var info fs.FileInfo
 
-	path := arg[0]
+	path := arg
 
 	if len(path) == 0 {
 		path = "."
@@ -243,28 +380,50 @@ func lsCmd(_ *shell.Interface, arg []string) (res string, err error) {
 	root, err := x64.UEFI.Root()
 
 	if err != nil {
-		return "", fmt.Errorf("could not open root volume, %v", err)
+		return nil, fmt.Errorf("could not open root volume, %v", err)
 	}
 
 	path = strings.ReplaceAll(path, `\`, `/`)
 	entries, err := fs.ReadDir(root, path)
 
 	if err != nil {
-		return "", fmt.Errorf("could not read directory, %v", err)
+		return nil, fmt.Errorf("could not read directory, %v", err)
 	}
 
+	files = []*File{}
+
 	for _, entry := range entries {
 		if info, err = entry.Info(); err != nil {
 			return
 		}
 
-		if info.IsDir() {
+		files = append(files, &File{
+			Name: entry.Name(),
+			Dir:  info.IsDir(),
+			Size: info.Size(),
+		})
+	}
+
+	return
+}
+
+func lsData(_ *shell.Interface, arg []string) (any, error) {
+	return listDir(arg[0])
+}
+
+func lsCmd(_ *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+
+	files, err := listDir(arg[0])
+
+	for _, f := range files {
+		if f.Dir {
 			fmt.Fprintf(&buf, "d ")
 		} else {
 			fmt.Fprintf(&buf, "f ")
 		}
 
-		fmt.Fprintf(&buf, "%s\n", entry.Name())
+		fmt.Fprintf(&buf, "%s\n", f.Name)
 	}
 
 	return buf.String(), err
@@ -323,28 +482,125 @@ func modeCmd(_ *shell.Interface, arg []string) (string, error) {
 	return "", x64.UEFI.Console.SetMode(mode)
 }
 
-func memmapCmd(_ *shell.Interface, arg []string) (res string, err error) {
+func gmodeCmd(c *shell.Interface, arg []string) (res string, err error) {
 	var buf bytes.Buffer
-	var memoryMap *uefi.MemoryMap
+	var gop *uefi.GraphicsOutput
+	var mode *uefi.ProtocolMode
+	var info *uefi.ModeInformation
 
-	if memoryMap, err = x64.UEFI.Boot.GetMemoryMap(); err != nil {
+	if gop, err = x64.UEFI.Boot.GetGraphicsOutput(); err != nil {
 		return
 	}
 
-	fmt.Fprintf(&buf, "Type Start            End              Pages            ")
+	if mode, err = gop.GetMode(); err != nil {
+		return
+	}
 
-	switch {
-	case arg[0] == "":
-		fmt.Fprintf(&buf, "Attributes\n")
-		for _, desc := range memoryMap.Descriptors {
-			fmt.Fprintf(&buf, "%02d   %016x %016x %016x %016x\n",
-				desc.Type, desc.PhysicalStart, desc.PhysicalEnd()-1, desc.NumberOfPages, desc.Attribute)
+	if len(arg[0]) > 0 {
+		n, e := strconv.ParseUint(arg[0], 10, 32)
+
+		if e != nil || n >= uint64(mode.MaxMode) {
+			return "", errors.New("invalid mode")
 		}
-	case arg[0] == " e820":
-		fmt.Fprintf(&buf, "\n")
+
+		if err = gop.SetMode(uint32(n)); err != nil {
+			return "", err
+		}
+
+		if Framebuffer != nil {
+			if err = Framebuffer.Init(); err != nil {
+				return "", err
+			}
+
+			if c.Terminal != nil {
+				c.Terminal.SetSize(Framebuffer.Size())
+			}
+		}
+
+		log.Printf("switched to EFI Graphics Output mode %d", n)
+
+		return "", nil
+	}
+
+	for i := uint32(0); i < mode.MaxMode; i++ {
+		if info, err = gop.QueryMode(i); err != nil {
+			return
+		}
+
+		current := " "
+
+		if i == mode.Mode {
+			current = "*"
+		}
+
+		fmt.Fprintf(&buf, "%s%3d: %5dx%-5d %-8s stride:%d\n", current, i,
+			info.HorizontalResolution, info.VerticalResolution, info.Format(), info.PixelsPerScanLine)
+	}
+
+	return buf.String(), nil
+}
+
+func memmapData(_ *shell.Interface, arg []string) (any, error) {
+	memoryMap, err := x64.UEFI.Boot.GetMemoryMap()
+
+	if err != nil {
+		return nil, err
+	}
+
+	regions := []*MemoryRegion{}
+
+	if arg[0] == "e820" {
 		for _, desc := range memoryMap.E820() {
-			fmt.Fprintf(&buf, "%02d   %016x %016x %016x\n",
-				desc.MemType, desc.Addr, desc.Addr+desc.Size-1, desc.Size/4096)
+			regions = append(regions, &MemoryRegion{
+				Type:  uint32(desc.MemType),
+				Start: desc.Addr,
+				End:   desc.Addr + desc.Size - 1,
+				Pages: desc.Size / 4096,
+			})
+		}
+
+		return regions, nil
+	}
+
+	for _, desc := range memoryMap.Descriptors {
+		regions = append(regions, &MemoryRegion{
+			Type:      desc.Type,
+			Start:     desc.PhysicalStart,
+			End:       desc.PhysicalEnd() - 1,
+			Pages:     desc.NumberOfPages,
+			Attribute: desc.Attribute,
+		})
+	}
+
+	return regions, nil
+}
+
+func memmapCmd(c *shell.Interface, arg []string) (res string, err error) {
+	var buf bytes.Buffer
+
+	data, err := memmapData(c, arg)
+
+	if err != nil {
+		return
+	}
+
+	e820 := arg[0] == "e820"
+
+	fmt.Fprintf(&buf, "Type Start            End              Pages            ")
+
+	if e820 {
+		fmt.Fprintf(&buf, "\n")
+	} else {
+		fmt.Fprintf(&buf, "Attributes\n")
+	}
+
+	for _, r := range data.([]*MemoryRegion) {
+		fmt.Fprintf(&buf, "%02d   %016x %016x %016x", r.Type, r.Start, r.End, r.Pages)
+
+		if e820 {
+			fmt.Fprintf(&buf, "\n")
+		} else {
+			fmt.Fprintf(&buf, " %016x\n", r.Attribute)
 		}
 	}
 
@@ -373,6 +629,41 @@ func shutdownCmd(_ *shell.Interface, _ []string) (_ string, err error) {
 	return resetCmd(nil, []string{"shutdown"})
 }
 
+func efivarData(_ *shell.Interface, arg []string) (any, error) {
+	var guid uefi.GUID
+	var name string
+	var err error
+
+	vars := []*Variable{}
+
+	for {
+		if err = x64.UEFI.Runtime.GetNextVariableName(&name, &guid); err != nil {
+			break
+		}
+
+		v := &Variable{
+			GUID: guid.String(),
+			Name: name,
+		}
+
+		if arg[0] == "verbose" {
+			if attr, _, err := x64.UEFI.Runtime.GetVariable(name, guid, false); err == nil {
+				v.Attributes = &attr
+			}
+		}
+
+		vars = append(vars, v)
+	}
+
+	// GetNextVariableName returns ErrEfiNotFound when there are no more
+	// variables
+	if !errors.Is(err, uefi.ErrEfiNotFound) {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
 func efivarCmd(_ *shell.Interface, arg []string) (res string, err error) {
 	var buf bytes.Buffer
 	var guid uefi.GUID
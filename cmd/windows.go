@@ -6,8 +6,6 @@
 package cmd
 
 import (
-	"regexp"
-
 	"github.com/usbarmory/go-boot/shell"
 )
 
@@ -15,10 +13,9 @@ const WindowsBootManager = `\EFI\Microsoft\Boot\bootmgfw.efi`
 
 func init() {
 	shell.Add(shell.Cmd{
-		Name:    "windows,win,w",
-		Pattern: regexp.MustCompile(`^(?:windows|win|w)$`),
-		Help:    "launch Windows UEFI boot manager",
-		Fn:      winCmd,
+		Name: "windows,win,w",
+		Help: "launch Windows UEFI boot manager",
+		Fn:   winCmd,
 	})
 }
 
@@ -0,0 +1,195 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+// Package edid implements parsing of VESA Extended Display Identification
+// Data (EDID) version 1.x base blocks.
+package edid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BlockSize represents the EDID base block size.
+const BlockSize = 128
+
+// EDID descriptor offsets and sizes
+const (
+	descriptorOffset = 54
+	descriptorSize   = 18
+	descriptors      = 4
+)
+
+// Display descriptor tags
+const (
+	tagSerial = 0xff
+	tagText   = 0xfe
+	tagName   = 0xfc
+)
+
+var header = []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}
+
+// Timing represents an EDID Detailed Timing Descriptor.
+type Timing struct {
+	// PixelClock is the pixel clock in kHz.
+	PixelClock uint32
+	HActive    int
+	HBlank     int
+	VActive    int
+	VBlank     int
+	WidthMM    int
+	HeightMM   int
+	Interlaced bool
+}
+
+// Refresh returns the vertical refresh rate in Hz.
+func (t *Timing) Refresh() float64 {
+	total := (t.HActive + t.HBlank) * (t.VActive + t.VBlank)
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(t.PixelClock) * 1000 / float64(total)
+}
+
+// String returns the timing resolution and refresh rate.
+func (t *Timing) String() string {
+	return fmt.Sprintf("%dx%d@%.2fHz", t.HActive, t.VActive, t.Refresh())
+}
+
+// EDID represents a parsed EDID base block.
+type EDID struct {
+	// Manufacturer is the three letter PNP vendor ID.
+	Manufacturer string
+	ProductCode  uint16
+	SerialNumber uint32
+	Week         int
+	Year         int
+	Version      int
+	Revision     int
+
+	// Width and Height are the physical display size in cm, zero values
+	// indicate unknown or variable size.
+	Width  int
+	Height int
+
+	// Name is the monitor name descriptor.
+	Name string
+	// Serial is the monitor serial number descriptor.
+	Serial string
+
+	// Timings are the Detailed Timing Descriptors, the first one
+	// represents the preferred (native) timing.
+	Timings []*Timing
+
+	// Extensions is the number of extension blocks.
+	Extensions int
+}
+
+// Parse parses an EDID base block.
+func Parse(buf []byte) (e *EDID, err error) {
+	var sum byte
+
+	if len(buf) < BlockSize || !bytes.Equal(buf[0:8], header) {
+		return nil, errors.New("invalid EDID header")
+	}
+
+	for _, b := range buf[0:BlockSize] {
+		sum += b
+	}
+
+	if sum != 0 {
+		return nil, errors.New("invalid EDID checksum")
+	}
+
+	id := uint16(buf[8])<<8 | uint16(buf[9])
+
+	e = &EDID{
+		Manufacturer: string([]byte{
+			'@' + byte(id>>10&0x1f),
+			'@' + byte(id>>5&0x1f),
+			'@' + byte(id&0x1f),
+		}),
+		ProductCode:  uint16(buf[10]) | uint16(buf[11])<<8,
+		SerialNumber: uint32(buf[12]) | uint32(buf[13])<<8 | uint32(buf[14])<<16 | uint32(buf[15])<<24,
+		Week:         int(buf[16]),
+		Year:         int(buf[17]) + 1990,
+		Version:      int(buf[18]),
+		Revision:     int(buf[19]),
+		Width:        int(buf[21]),
+		Height:       int(buf[22]),
+		Extensions:   int(buf[126]),
+	}
+
+	for i := 0; i < descriptors; i++ {
+		d := buf[descriptorOffset+i*descriptorSize:][:descriptorSize]
+
+		if d[0] != 0 || d[1] != 0 {
+			e.Timings = append(e.Timings, parseTiming(d))
+			continue
+		}
+
+		switch d[3] {
+		case tagName:
+			e.Name = descriptorText(d)
+		case tagSerial:
+			e.Serial = descriptorText(d)
+		}
+	}
+
+	return
+}
+
+func parseTiming(d []byte) *Timing {
+	return &Timing{
+		PixelClock: (uint32(d[0]) | uint32(d[1])<<8) * 10,
+		HActive:    int(d[2]) | int(d[4]&0xf0)<<4,
+		HBlank:     int(d[3]) | int(d[4]&0x0f)<<8,
+		VActive:    int(d[5]) | int(d[7]&0xf0)<<4,
+		VBlank:     int(d[6]) | int(d[7]&0x0f)<<8,
+		WidthMM:    int(d[12]) | int(d[14]&0xf0)<<4,
+		HeightMM:   int(d[13]) | int(d[14]&0x0f)<<8,
+		Interlaced: d[17]&0x80 != 0,
+	}
+}
+
+func descriptorText(d []byte) string {
+	s, _, _ := strings.Cut(string(d[5:descriptorSize]), "\n")
+	return strings.TrimSpace(s)
+}
+
+// Preferred returns the preferred (native) timing, if available.
+func (e *EDID) Preferred() *Timing {
+	if len(e.Timings) == 0 {
+		return nil
+	}
+
+	return e.Timings[0]
+}
+
+// DPI returns the horizontal and vertical dots per inch of the preferred
+// timing, zero values are returned when the physical size is unknown.
+func (e *EDID) DPI() (x int, y int) {
+	t := e.Preferred()
+
+	if t == nil {
+		return
+	}
+
+	w, h := t.WidthMM, t.HeightMM
+
+	if w == 0 || h == 0 {
+		w, h = e.Width*10, e.Height*10
+	}
+
+	if w == 0 || h == 0 {
+		return
+	}
+
+	return t.HActive * 254 / (w * 10), t.VActive * 254 / (h * 10)
+}
@@ -0,0 +1,468 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+// Package fb implements a text console rendered with a bitmap font on the EFI
+// Graphics Output Protocol, for use on firmware which only provides low
+// resolution text modes.
+package fb
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/usbarmory/go-boot/uefi"
+	"github.com/usbarmory/go-boot/vt100"
+)
+
+// Minimum console size used to select the font scaling factor when
+// [Console.Scale] is not set.
+var (
+	MinColumns = 100
+	MinRows    = 30
+)
+
+// DefaultScrollback represents the number of off-screen lines retained when
+// [Console.Scrollback] is not set.
+var DefaultScrollback = 1000
+
+// Palette represents the console colors (0xRRGGBB), the first eight entries
+// are the ANSI colors followed by their bright variants.
+var Palette = [16]uint32{
+	0x000000, 0xaa0000, 0x00aa00, 0xaa5500, 0x0000aa, 0xaa00aa, 0x00aaaa, 0xaaaaaa,
+	0x555555, 0xff5555, 0x55ff55, 0xffff55, 0x5555ff, 0xff55ff, 0x55ffff, 0xffffff,
+}
+
+// Default console colors (as [Palette] indices)
+const (
+	DefaultForeground = 7
+	DefaultBackground = 0
+)
+
+const (
+	// tab stop width
+	tabSize = 8
+	// EFI_GRAPHICS_OUTPUT_BLT_PIXEL size
+	pixelSize = 4
+)
+
+type cell struct {
+	r  rune
+	fg uint8
+	bg uint8
+}
+
+// Console represents a text console rendered on an EFI Graphics Output
+// Protocol instance, it implements [io.ReadWriter] with VT100 output
+// sequences for colors, cursor movement and erasing.
+//
+// Output is drawn with Blt() calls, the console therefore must be silenced,
+// by setting GOP to nil, before exiting EFI Boot Services.
+type Console struct {
+	// GOP represents the graphics output, output is not rendered when nil.
+	GOP *uefi.GraphicsOutput
+	// Input represents the console input (e.g. [uefi.Console]).
+	Input io.Reader
+
+	// Font represents the console font, [DefaultFont] is used when nil.
+	Font *Font
+	// Scale represents the font scaling factor, when zero it is selected
+	// on [Console.Init] to fit at least [MinColumns] x [MinRows].
+	Scale int
+	// Scrollback represents the number of off-screen lines retained for
+	// scrolling back, [DefaultScrollback] is used when zero.
+	Scrollback int
+
+	mu sync.Mutex
+
+	// screen and cell size in pixels
+	width  int
+	height int
+	cw     int
+	ch     int
+
+	// screen size in cells
+	cols int
+	rows int
+
+	// history and screen lines
+	lines [][]cell
+	// scrollback view offset
+	view int
+
+	// cursor position and state
+	col    int
+	row    int
+	hidden bool
+
+	// current colors
+	fg uint8
+	bg uint8
+
+	// VT100 output sequence parser
+	vt vt100.Parser
+	// pending UTF-8 bytes
+	utf []byte
+	// pending standard output line
+	stdout    [256]byte
+	stdoutLen int
+
+	// Blt buffer
+	buf []byte
+}
+
+// Init initializes the console for the current graphics mode, clearing the
+// screen.
+func (c *Console) Init() (err error) {
+	var mode *uefi.ProtocolMode
+	var info *uefi.ModeInformation
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.GOP == nil {
+		return errors.New("missing graphics output")
+	}
+
+	if mode, err = c.GOP.GetMode(); err != nil {
+		return
+	}
+
+	if info, err = mode.GetInfo(); err != nil {
+		return
+	}
+
+	if c.Font == nil {
+		c.Font = DefaultFont
+	}
+
+	if c.Scrollback == 0 {
+		c.Scrollback = DefaultScrollback
+	}
+
+	c.width = int(info.HorizontalResolution)
+	c.height = int(info.VerticalResolution)
+
+	scale := c.Scale
+
+	if scale <= 0 {
+		scale = min(c.width/(c.Font.Width*MinColumns), c.height/(c.Font.Height*MinRows))
+	}
+
+	scale = max(1, scale)
+
+	c.cw = c.Font.Width * scale
+	c.ch = c.Font.Height * scale
+	c.cols = c.width / c.cw
+	c.rows = c.height / c.ch
+
+	if c.cols == 0 || c.rows == 0 {
+		return errors.New("font exceeds screen size")
+	}
+
+	c.fg = DefaultForeground
+	c.bg = DefaultBackground
+	c.vt.Reset()
+	c.utf = nil
+	c.stdoutLen = 0
+	c.lines = nil
+
+	c.clearScreen()
+
+	return
+}
+
+// Size returns the console size in characters.
+func (c *Console) Size() (cols int, rows int) {
+	return c.cols, c.rows
+}
+
+// Read available data to buffer from console input.
+func (c *Console) Read(p []byte) (n int, err error) {
+	if c.Input == nil {
+		return 0, io.EOF
+	}
+
+	return c.Input.Read(p)
+}
+
+// Write data from buffer to console, VT100 Control Sequence Introducer (CSI)
+// sequences for colors, cursor movement and erasing are interpreted.
+func (c *Console) Write(p []byte) (n int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.flush()
+	c.write(p)
+
+	return len(p), nil
+}
+
+// Printk buffers standard output characters, which are rendered on each line
+// feed or when the buffer is full (e.g. as `x64.Stdout`).
+func (c *Console) Printk(b byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stdout[c.stdoutLen] = b
+	c.stdoutLen += 1
+
+	if b == '\n' || c.stdoutLen == len(c.stdout) {
+		c.flush()
+	}
+}
+
+// flush renders the pending standard output line.
+func (c *Console) flush() {
+	if c.stdoutLen > 0 {
+		n := c.stdoutLen
+		c.stdoutLen = 0
+		c.write(c.stdout[:n])
+	}
+}
+
+func (c *Console) write(p []byte) {
+	if c.cols == 0 {
+		return
+	}
+
+	if c.view > 0 {
+		c.view = 0
+		c.redraw()
+	}
+
+	c.drawCursor(false)
+	defer c.drawCursor(true)
+
+	c.vt.Write((*screen)(c), p)
+}
+
+// Keys implements a [uefi.Console] key filter to scroll back the console
+// history with the Page Up and Page Down keys.
+func (c *Console) Keys(k *uefi.KeyData) (buf []byte, ok bool) {
+	switch k.Key.ScanCode {
+	case uefi.SCAN_PAGE_UP:
+		c.Scroll(c.rows / 2)
+	case uefi.SCAN_PAGE_DOWN:
+		c.Scroll(-c.rows / 2)
+	default:
+		return nil, false
+	}
+
+	return nil, true
+}
+
+// Scroll moves the console view by the argument number of lines, positive
+// values scroll back through history.
+func (c *Console) Scroll(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	view := max(0, min(c.view+n, len(c.lines)-c.rows))
+
+	if view == c.view {
+		return
+	}
+
+	c.view = view
+	c.redraw()
+	c.drawCursor(true)
+}
+
+// line returns the argument screen row line.
+func (c *Console) line(row int) []cell {
+	return c.lines[len(c.lines)-c.rows+row]
+}
+
+func (c *Console) blank() cell {
+	return cell{r: ' ', fg: c.fg, bg: c.bg}
+}
+
+func (c *Console) newLine() []cell {
+	line := make([]cell, c.cols)
+
+	for i := range line {
+		line[i] = c.blank()
+	}
+
+	return line
+}
+
+// control handles ASCII characters.
+func (c *Console) control(r rune) {
+	switch r {
+	case '\r':
+		c.col = 0
+	case '\n':
+		c.col = 0
+		c.lineFeed()
+	case '\b':
+		c.col = max(0, min(c.col, c.cols-1)-1)
+	case '\t':
+		c.col = min(c.cols-1, (c.col/tabSize+1)*tabSize)
+	default:
+		if r >= ' ' && r != 0x7f {
+			c.put(r)
+		}
+	}
+}
+
+// put outputs a printable character at the cursor position, lines are
+// wrapped only when the next character is written (as VT100 terminals do).
+func (c *Console) put(r rune) {
+	if c.col >= c.cols {
+		c.col = 0
+		c.lineFeed()
+	}
+
+	line := c.line(c.row)
+	line[c.col] = cell{r: r, fg: c.fg, bg: c.bg}
+	c.draw(c.row, c.col, c.col+1)
+
+	c.col += 1
+}
+
+// lineFeed moves the cursor to the next line, scrolling the screen when
+// required.
+func (c *Console) lineFeed() {
+	if c.row < c.rows-1 {
+		c.row += 1
+		return
+	}
+
+	c.lines = append(c.lines, c.newLine())
+
+	if n := len(c.lines) - c.rows - c.Scrollback; n > 0 {
+		c.lines = c.lines[n:]
+	}
+
+	// move screen content up by one line
+	c.blt(uefi.EfiBltVideoToVideo, 0, c.ch, 0, 0, c.width, (c.rows-1)*c.ch, 0)
+	c.fill(0, c.rows-1, c.cols, c.bg)
+}
+
+// clearScreen clears the screen, preserving its content in history.
+func (c *Console) clearScreen() {
+	for i := 0; i < c.rows; i++ {
+		c.lines = append(c.lines, c.newLine())
+	}
+
+	if n := len(c.lines) - c.rows - c.Scrollback; n > 0 {
+		c.lines = c.lines[n:]
+	}
+
+	c.col = 0
+	c.row = 0
+	c.view = 0
+
+	c.blt(uefi.EfiBltVideoFill, 0, 0, 0, 0, c.width, c.height, c.bg)
+}
+
+// erase blanks cells from start to end (excluded) of the argument screen row.
+func (c *Console) erase(row int, start int, end int) {
+	line := c.line(row)
+
+	for i := start; i < end; i++ {
+		line[i] = c.blank()
+	}
+
+	c.fill(start, row, end-start, c.bg)
+}
+
+// redraw renders all screen rows.
+func (c *Console) redraw() {
+	for row := 0; row < c.rows; row++ {
+		c.draw(row, 0, c.cols)
+	}
+}
+
+// drawCursor renders the cursor cell, inverting its colors when the argument
+// is true.
+func (c *Console) drawCursor(on bool) {
+	if c.hidden || c.view > 0 {
+		return
+	}
+
+	col := min(c.col, c.cols-1)
+	cur := c.line(c.row)[col]
+
+	if on {
+		cur.fg, cur.bg = cur.bg, cur.fg
+	}
+
+	c.render([]cell{cur}, col*c.cw, c.row*c.ch)
+}
+
+// draw renders cells from start to end (excluded) of the argument screen row.
+func (c *Console) draw(row int, start int, end int) {
+	line := c.lines[len(c.lines)-c.rows-c.view+row]
+	c.render(line[start:end], start*c.cw, row*c.ch)
+}
+
+// render draws a sequence of cells at the argument pixel coordinates.
+func (c *Console) render(cells []cell, x int, y int) {
+	var fg, bg [pixelSize]byte
+
+	if c.GOP == nil {
+		return
+	}
+
+	f := c.Font
+	scale := c.cw / f.Width
+	w := len(cells) * c.cw
+	stride := w * pixelSize
+
+	if size := stride * c.ch; len(c.buf) < size {
+		c.buf = make([]byte, size)
+	}
+
+	for i, cell := range cells {
+		glyph := f.Glyph(cell.r)
+		fg = pixel(cell.fg)
+		bg = pixel(cell.bg)
+
+		for py := 0; py < c.ch; py++ {
+			off := py*stride + i*c.cw*pixelSize
+
+			for px := 0; px < c.cw; px++ {
+				p := c.buf[off+px*pixelSize : off+(px+1)*pixelSize]
+
+				if f.Set(glyph, px/scale, py/scale) {
+					copy(p, fg[:])
+				} else {
+					copy(p, bg[:])
+				}
+			}
+		}
+	}
+
+	c.GOP.Blt(c.buf, uefi.EfiBltBufferToVideo, 0, 0, uint64(x), uint64(y), uint64(w), uint64(c.ch), uint64(stride))
+}
+
+// fill paints n cells, from the argument cell position, with a color.
+func (c *Console) fill(col int, row int, n int, color uint8) {
+	if n <= 0 {
+		return
+	}
+
+	c.blt(uefi.EfiBltVideoFill, 0, 0, col*c.cw, row*c.ch, n*c.cw, c.ch, color)
+}
+
+// blt performs a Blt() video operation, the color is used for video fills.
+func (c *Console) blt(op uefi.BltOperation, srcX, srcY, dstX, dstY, width, height int, color uint8) {
+	if c.GOP == nil || width <= 0 || height <= 0 {
+		return
+	}
+
+	p := pixel(color)
+
+	c.GOP.Blt(p[:], op, uint64(srcX), uint64(srcY), uint64(dstX), uint64(dstY), uint64(width), uint64(height), 0)
+}
+
+// pixel converts a palette index to an EFI_GRAPHICS_OUTPUT_BLT_PIXEL.
+func pixel(color uint8) [pixelSize]byte {
+	rgb := Palette[color&0x0f]
+	return [pixelSize]byte{byte(rgb), byte(rgb >> 8), byte(rgb >> 16), 0}
+}
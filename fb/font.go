@@ -0,0 +1,211 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package fb
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// PC Screen Font magic numbers
+const (
+	psf1Magic = 0x0436
+	psf2Magic = 0x864ab572
+)
+
+// PC Screen Font flags
+const (
+	psf1ModeHasTab = 0x02
+	psf2HasUnicode = 0x01
+)
+
+// PC Screen Font Unicode table separators
+const (
+	psf1Separator = 0xffff
+	psf1StartSeq  = 0xfffe
+	psf2Separator = 0xff
+	psf2StartSeq  = 0xfe
+)
+
+// defaultFont is a 8x16 PSF2 font derived from the public domain X11
+// misc-fixed 6x13 font.
+//
+//go:embed font.psf
+var defaultFont []byte
+
+// DefaultFont represents the built-in console font.
+var DefaultFont *Font
+
+// Font represents a PC Screen Font (PSF) bitmap font.
+type Font struct {
+	// Width is the glyph width in pixels.
+	Width int
+	// Height is the glyph height in pixels.
+	Height int
+
+	stride int
+	size   int
+	glyphs []byte
+	table  map[rune]int
+}
+
+func init() {
+	var err error
+
+	if DefaultFont, err = ParseFont(defaultFont); err != nil {
+		panic(err)
+	}
+}
+
+// ParseFont parses a PC Screen Font (version 1 or 2), when a Unicode table is
+// not present glyphs are mapped to Latin-1 code points.
+func ParseFont(buf []byte) (f *Font, err error) {
+	var n int
+	var psf1 bool
+	var table []byte
+
+	f = &Font{}
+
+	switch {
+	case len(buf) >= 4 && binary.LittleEndian.Uint16(buf) == psf1Magic:
+		mode := buf[2]
+		psf1 = true
+
+		f.Width = 8
+		f.Height = int(buf[3])
+		f.stride = 1
+		f.size = f.Height
+
+		if n = 256; mode&0x01 != 0 {
+			n = 512
+		}
+
+		if end := 4 + n*f.size; len(buf) >= end {
+			f.glyphs = buf[4:end]
+
+			if mode&psf1ModeHasTab != 0 {
+				table = buf[end:]
+			}
+		}
+	case len(buf) >= 32 && binary.LittleEndian.Uint32(buf) == psf2Magic:
+		hdr := make([]uint32, 8)
+
+		if _, err = binary.Decode(buf, binary.LittleEndian, hdr); err != nil {
+			return nil, err
+		}
+
+		off := int(hdr[2])
+		flags := hdr[3]
+		n = int(hdr[4])
+		f.size = int(hdr[5])
+		f.Height = int(hdr[6])
+		f.Width = int(hdr[7])
+		f.stride = (f.Width + 7) / 8
+
+		if f.size < f.stride*f.Height || n > 1<<16 {
+			return nil, errors.New("invalid PSF2 header")
+		}
+
+		if end := off + n*f.size; off >= 32 && len(buf) >= end {
+			f.glyphs = buf[off:end]
+
+			if flags&psf2HasUnicode != 0 {
+				table = buf[end:]
+			}
+		}
+	default:
+		return nil, errors.New("invalid PSF magic")
+	}
+
+	if f.glyphs == nil || f.Width == 0 || f.Height == 0 {
+		return nil, fmt.Errorf("invalid font size (%dx%d, %d glyphs)", f.Width, f.Height, n)
+	}
+
+	f.table = make(map[rune]int)
+
+	switch {
+	case table == nil:
+		for i := 0; i < n; i++ {
+			f.table[rune(i)] = i
+		}
+	case psf1:
+		f.parseTable1(table, n)
+	default:
+		f.parseTable2(table, n)
+	}
+
+	return
+}
+
+// parseTable1 parses a PSF1 Unicode table, multi code point sequences are
+// ignored.
+func (f *Font) parseTable1(table []byte, n int) {
+	var seq bool
+
+	for i := 0; i < n && len(table) >= 2; table = table[2:] {
+		switch r := binary.LittleEndian.Uint16(table); r {
+		case psf1Separator:
+			i += 1
+			seq = false
+		case psf1StartSeq:
+			seq = true
+		default:
+			if !seq {
+				f.table[rune(r)] = i
+			}
+		}
+	}
+}
+
+// parseTable2 parses a PSF2 Unicode table, multi code point sequences are
+// ignored.
+func (f *Font) parseTable2(table []byte, n int) {
+	var seq bool
+
+	for i := 0; i < n && len(table) > 0; {
+		switch table[0] {
+		case psf2Separator:
+			i += 1
+			seq = false
+			table = table[1:]
+		case psf2StartSeq:
+			seq = true
+			table = table[1:]
+		default:
+			r, size := utf8.DecodeRune(table)
+
+			if !seq && (r != utf8.RuneError || size > 1) {
+				f.table[r] = i
+			}
+
+			table = table[size:]
+		}
+	}
+}
+
+// Glyph returns the bitmap for the argument rune, or for the Unicode
+// replacement character when the rune is not available, rows are Width bits
+// long and padded to the next byte.
+func (f *Font) Glyph(r rune) []byte {
+	i, ok := f.table[r]
+
+	if !ok {
+		if i, ok = f.table[utf8.RuneError]; !ok {
+			i = f.table['?']
+		}
+	}
+
+	return f.glyphs[i*f.size : (i+1)*f.size]
+}
+
+// Set returns whether the pixel at the argument coordinates of a glyph
+// bitmap is set.
+func (f *Font) Set(glyph []byte, x int, y int) bool {
+	return glyph[y*f.stride+x/8]&(0x80>>(x%8)) != 0
+}
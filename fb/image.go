@@ -0,0 +1,312 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package fb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/usbarmory/go-boot/uefi"
+)
+
+// BMP header sizes
+const (
+	bmpFileHeaderSize = 14
+	bmpInfoHeaderSize = 40
+)
+
+// BMP compression methods
+const (
+	biRGB       = 0
+	biBitFields = 3
+)
+
+// ParseColor parses a color in hexadecimal RRGGBB format, with an optional
+// leading `#`.
+func ParseColor(s string) (rgb uint32, err error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+
+	if err != nil || n > 0xffffff {
+		return 0, fmt.Errorf("invalid color %s", s)
+	}
+
+	return uint32(n), nil
+}
+
+// Decode decodes a PNG or BMP image.
+func Decode(buf []byte) (img image.Image, err error) {
+	switch {
+	case bytes.HasPrefix(buf, []byte("\x89PNG\r\n\x1a\n")):
+		return png.Decode(bytes.NewReader(buf))
+	case bytes.HasPrefix(buf, []byte("BM")):
+		return DecodeBMP(buf)
+	default:
+		return nil, errors.New("unsupported image format")
+	}
+}
+
+// DecodeBMP decodes an uncompressed 8, 24 or 32 bits per pixel BMP image.
+func DecodeBMP(buf []byte) (img *image.RGBA, err error) {
+	var palette []color.RGBA
+
+	if len(buf) < bmpFileHeaderSize+bmpInfoHeaderSize || !bytes.HasPrefix(buf, []byte("BM")) {
+		return nil, errors.New("invalid BMP header")
+	}
+
+	off := int(binary.LittleEndian.Uint32(buf[10:]))
+	dibSize := int(binary.LittleEndian.Uint32(buf[14:]))
+	width := int(int32(binary.LittleEndian.Uint32(buf[18:])))
+	height := int(int32(binary.LittleEndian.Uint32(buf[22:])))
+	bpp := int(binary.LittleEndian.Uint16(buf[28:]))
+	compression := binary.LittleEndian.Uint32(buf[30:])
+	colors := int(binary.LittleEndian.Uint32(buf[46:]))
+
+	topDown := height < 0
+
+	if topDown {
+		height = -height
+	}
+
+	switch {
+	case dibSize < bmpInfoHeaderSize:
+		return nil, fmt.Errorf("unsupported BMP header size (%d)", dibSize)
+	case width <= 0 || height <= 0 || width > 1<<14 || height > 1<<14:
+		return nil, fmt.Errorf("invalid BMP size (%dx%d)", width, height)
+	case compression != biRGB && !(compression == biBitFields && bpp == 32):
+		return nil, fmt.Errorf("unsupported BMP compression (%d)", compression)
+	case bpp != 8 && bpp != 24 && bpp != 32:
+		return nil, fmt.Errorf("unsupported BMP depth (%d)", bpp)
+	}
+
+	if bpp == 8 {
+		if colors == 0 {
+			colors = 256
+		}
+
+		start := bmpFileHeaderSize + dibSize
+
+		for i := 0; i < colors && start+i*4+4 <= len(buf); i++ {
+			p := buf[start+i*4:]
+			palette = append(palette, color.RGBA{p[2], p[1], p[0], 0xff})
+		}
+	}
+
+	stride := (width*bpp/8 + 3) &^ 3
+
+	if off < 0 || off+stride*height > len(buf) {
+		return nil, errors.New("invalid BMP size")
+	}
+
+	img = image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		row := y
+
+		if !topDown {
+			row = height - 1 - y
+		}
+
+		line := buf[off+row*stride:]
+
+		for x := 0; x < width; x++ {
+			var c color.RGBA
+
+			switch bpp {
+			case 8:
+				if i := int(line[x]); i < len(palette) {
+					c = palette[i]
+				}
+			case 24:
+				p := line[x*3:]
+				c = color.RGBA{p[2], p[1], p[0], 0xff}
+			case 32:
+				p := line[x*4:]
+				c = color.RGBA{p[2], p[1], p[0], 0xff}
+			}
+
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return
+}
+
+// EncodeBMP encodes an image in 24 bits per pixel BMP format.
+func EncodeBMP(img image.Image) []byte {
+	b := img.Bounds()
+	w := b.Dx()
+	h := b.Dy()
+
+	stride := (w*3 + 3) &^ 3
+	off := bmpFileHeaderSize + bmpInfoHeaderSize
+	buf := make([]byte, off+stride*h)
+
+	copy(buf, "BM")
+	binary.LittleEndian.PutUint32(buf[2:], uint32(len(buf)))
+	binary.LittleEndian.PutUint32(buf[10:], uint32(off))
+	binary.LittleEndian.PutUint32(buf[14:], bmpInfoHeaderSize)
+	binary.LittleEndian.PutUint32(buf[18:], uint32(w))
+	// negative height for top-down row order
+	binary.LittleEndian.PutUint32(buf[22:], uint32(-int32(h)))
+	binary.LittleEndian.PutUint16(buf[26:], 1)
+	binary.LittleEndian.PutUint16(buf[28:], 24)
+	binary.LittleEndian.PutUint32(buf[34:], uint32(stride*h))
+
+	for y := 0; y < h; y++ {
+		line := buf[off+y*stride:]
+
+		for x := 0; x < w; x++ {
+			c := color.RGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA)
+			copy(line[x*3:], []byte{c.B, c.G, c.R})
+		}
+	}
+
+	return buf
+}
+
+// Capture reads a screen area through EFI_GRAPHICS_OUTPUT_PROTOCOL.Blt().
+func Capture(gop *uefi.GraphicsOutput, x int, y int, width int, height int) (img *image.RGBA, err error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("invalid capture size")
+	}
+
+	buf := make([]byte, width*height*pixelSize)
+
+	if err = gop.Blt(buf, uefi.EfiBltVideoToBltBuffer, uint64(x), uint64(y), 0, 0, uint64(width), uint64(height), uint64(width*pixelSize)); err != nil {
+		return
+	}
+
+	img = image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for i := 0; i < width*height; i++ {
+		p := buf[i*pixelSize:]
+		copy(img.Pix[i*4:], []byte{p[2], p[1], p[0], 0xff})
+	}
+
+	return
+}
+
+// Draw renders an image at the argument screen coordinates, images with
+// transparency are blended over the current screen content.
+func Draw(gop *uefi.GraphicsOutput, img image.Image, x int, y int) (err error) {
+	b := img.Bounds()
+	w := b.Dx()
+	h := b.Dy()
+
+	if w == 0 || h == 0 {
+		return
+	}
+
+	var dst *image.RGBA
+
+	if op, ok := img.(interface{ Opaque() bool }); ok && op.Opaque() {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	} else if dst, err = Capture(gop, x, y, w, h); err != nil {
+		return
+	}
+
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+
+	buf := make([]byte, w*h*pixelSize)
+
+	for i := 0; i < w*h; i++ {
+		p := dst.Pix[i*4:]
+		copy(buf[i*pixelSize:], []byte{p[2], p[1], p[0], 0})
+	}
+
+	return gop.Blt(buf, uefi.EfiBltBufferToVideo, 0, 0, uint64(x), uint64(y), uint64(w), uint64(h), uint64(w*pixelSize))
+}
+
+// Fill paints a screen area with an RRGGBB color.
+func Fill(gop *uefi.GraphicsOutput, rgb uint32, x int, y int, width int, height int) (err error) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	p := []byte{byte(rgb), byte(rgb >> 8), byte(rgb >> 16), 0}
+
+	return gop.Blt(p, uefi.EfiBltVideoFill, 0, 0, uint64(x), uint64(y), uint64(width), uint64(height), 0)
+}
+
+// DrawText renders a single line of text at the argument screen coordinates
+// with RRGGBB colors, it returns the rendered width in pixels.
+func DrawText(gop *uefi.GraphicsOutput, f *Font, scale int, s string, x int, y int, fg uint32, bg uint32) (width int, err error) {
+	scale = max(1, scale)
+	runes := []rune(s)
+
+	cw := f.Width * scale
+	ch := f.Height * scale
+	width = len(runes) * cw
+	stride := width * pixelSize
+
+	if width == 0 {
+		return
+	}
+
+	fgp := []byte{byte(fg), byte(fg >> 8), byte(fg >> 16), 0}
+	bgp := []byte{byte(bg), byte(bg >> 8), byte(bg >> 16), 0}
+	buf := make([]byte, stride*ch)
+
+	for i, r := range runes {
+		glyph := f.Glyph(r)
+
+		for py := 0; py < ch; py++ {
+			off := py*stride + i*cw*pixelSize
+
+			for px := 0; px < cw; px++ {
+				if f.Set(glyph, px/scale, py/scale) {
+					copy(buf[off+px*pixelSize:], fgp)
+				} else {
+					copy(buf[off+px*pixelSize:], bgp)
+				}
+			}
+		}
+	}
+
+	err = gop.Blt(buf, uefi.EfiBltBufferToVideo, 0, 0, uint64(x), uint64(y), uint64(width), uint64(ch), uint64(stride))
+
+	return
+}
+
+// fit returns the argument image, downscaled when exceeding the argument
+// size while preserving its aspect ratio.
+func fit(img image.Image, width int, height int) image.Image {
+	b := img.Bounds()
+
+	if b.Dx() <= width && b.Dy() <= height {
+		return img
+	}
+
+	w := width
+	h := b.Dy() * width / b.Dx()
+
+	if h > height {
+		w = b.Dx() * height / b.Dy()
+		h = height
+	}
+
+	w = max(1, w)
+	h = max(1, h)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	// nearest neighbour sampling
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+
+	return dst
+}
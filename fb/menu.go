@@ -0,0 +1,546 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package fb
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/usbarmory/go-boot/uefi"
+)
+
+// Menu pointer polling interval
+var PollInterval = 10 * time.Millisecond
+
+const (
+	// pointer movement in pixels per millimeter
+	pointerScale = 4
+	// pointer arrow bitmap width
+	arrowWidth = 10
+)
+
+// menu key actions
+const (
+	keyUp = iota
+	keyDown
+	keyFirst
+	keyLast
+	keyEnter
+	keyEscape
+)
+
+// VT100 input sequences for menu key actions
+var menuKeys = []struct {
+	seq    string
+	action int
+}{
+	{"\x1b[A", keyUp},
+	{"\x1bOA", keyUp},
+	{"\x1b[B", keyDown},
+	{"\x1bOB", keyDown},
+	{"\x1b[H", keyFirst},
+	{"\x1b[5~", keyFirst},
+	{"\x1b[F", keyLast},
+	{"\x1b[6~", keyLast},
+	{"\r", keyEnter},
+	{"\n", keyEnter},
+	// alternatives to escape sequences, which lockdown mode discards
+	{"k", keyUp},
+	{"j", keyDown},
+	{"q", keyEscape},
+}
+
+// pointer arrow bitmap (X: outline, O: fill)
+var arrow = []string{
+	"X",
+	"XX",
+	"XOX",
+	"XOOX",
+	"XOOOX",
+	"XOOOOX",
+	"XOOOOOX",
+	"XOOOOOOX",
+	"XOOOOOOOX",
+	"XOOOOXXXXX",
+	"XOOXOOX",
+	"XOX XOOX",
+	"XX  XOOX",
+	"X    XOOX",
+	"     XOOX",
+	"      XX",
+}
+
+// MenuEntry represents a graphical boot menu entry.
+type MenuEntry struct {
+	// Title is the entry label.
+	Title string
+	// Icon is an optional image shown before the title.
+	Icon image.Image
+}
+
+// Menu represents a graphical boot menu, driven by keyboard and (optionally)
+// pointer input.
+type Menu struct {
+	// GOP represents the graphics output.
+	GOP *uefi.GraphicsOutput
+	// Input represents the keyboard input as VT100 sequences (e.g.
+	// [shell.Interface.Input]).
+	Input <-chan []byte
+	// Pointer represents the optional pointer (mouse) input.
+	Pointer *uefi.SimplePointer
+
+	// Font represents the menu font, [DefaultFont] is used when nil.
+	Font *Font
+	// Scale represents the font scaling factor, when zero it is selected
+	// according to the screen height.
+	Scale int
+
+	// Splash is an optional image shown above the entries.
+	Splash image.Image
+	// Menu colors (0xRRGGBB)
+	Background uint32
+	Foreground uint32
+	Highlight  uint32
+
+	// Entries represents the menu entries.
+	Entries []*MenuEntry
+	// Default is the initially selected entry index.
+	Default int
+	// Timeout represents the time after which the selected entry is
+	// chosen, no timeout is applied when zero. The countdown stops on
+	// any input.
+	Timeout time.Duration
+
+	width  int
+	height int
+
+	// entry list geometry
+	x     int
+	y     int
+	w     int
+	rowH  int
+	iconW int
+
+	selected int
+	// first and number of entries on screen
+	first   int
+	visible int
+	// countdown remaining seconds
+	left int
+
+	// pointer position and saved background
+	px    int
+	py    int
+	under *image.RGBA
+}
+
+// Run displays the menu and returns the chosen entry index, or -1 when the
+// menu is dismissed with the Escape key.
+func (m *Menu) Run() (index int, err error) {
+	var deadline time.Time
+	var mode *uefi.ProtocolMode
+	var info *uefi.ModeInformation
+	var pointerMode *uefi.PointerMode
+	var countdown <-chan time.Time
+	var poll <-chan time.Time
+
+	if m.GOP == nil || len(m.Entries) == 0 {
+		return -1, errors.New("invalid menu")
+	}
+
+	if m.Input == nil && m.Pointer == nil && m.Timeout <= 0 {
+		return -1, errors.New("no menu input")
+	}
+
+	if mode, err = m.GOP.GetMode(); err != nil {
+		return
+	}
+
+	if info, err = mode.GetInfo(); err != nil {
+		return
+	}
+
+	m.width = int(info.HorizontalResolution)
+	m.height = int(info.VerticalResolution)
+	m.selected = max(0, min(m.Default, len(m.Entries)-1))
+
+	if m.Font == nil {
+		m.Font = DefaultFont
+	}
+
+	if m.Scale <= 0 {
+		m.Scale = max(1, m.height/(m.Font.Height*MinRows))
+	}
+
+	if err = m.draw(); err != nil {
+		return
+	}
+
+	if m.Pointer != nil {
+		if pointerMode, err = m.Pointer.GetMode(); err != nil {
+			m.Pointer = nil
+			err = nil
+		} else {
+			m.px = m.width / 2
+			m.py = m.height / 2
+			m.Pointer.Reset()
+
+			t := time.NewTicker(PollInterval)
+			defer t.Stop()
+
+			poll = t.C
+		}
+	}
+
+	if m.Timeout > 0 {
+		deadline = time.Now().Add(m.Timeout)
+
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+
+		countdown = t.C
+		m.drawCountdown(deadline)
+	}
+
+	defer m.hidePointer()
+
+	for {
+		var input bool
+
+		select {
+		case buf, ok := <-m.Input:
+			if !ok {
+				m.Input = nil
+
+				if poll == nil && countdown == nil {
+					return -1, errors.New("menu input closed")
+				}
+
+				continue
+			}
+
+			m.stopCountdown()
+			countdown = nil
+
+			if index = m.keys(string(buf)); index != -2 {
+				return
+			}
+		case <-poll:
+			if index, input = m.pointer(pointerMode); input {
+				m.stopCountdown()
+				countdown = nil
+
+				if index >= 0 {
+					return
+				}
+			}
+		case <-countdown:
+			if !m.drawCountdown(deadline) {
+				return m.selected, nil
+			}
+		}
+	}
+}
+
+// drawCountdown renders the remaining time before the selected entry is
+// chosen, it returns false once the deadline has passed.
+func (m *Menu) drawCountdown(deadline time.Time) bool {
+	left := int(time.Until(deadline).Seconds() + 0.999)
+
+	if left <= 0 {
+		return false
+	}
+
+	if left != m.left {
+		m.left = left
+		m.drawStatus(fmt.Sprintf("%s in %ds", m.Entries[m.selected].Title, left))
+	}
+
+	return true
+}
+
+// keys handles VT100 input sequences, it returns the chosen index (-1 on
+// Escape and -2 for selection changes).
+func (m *Menu) keys(s string) int {
+	sel := m.selected
+
+	for len(s) > 0 {
+		action := -1
+
+		if s == "\x1b" {
+			action = keyEscape
+		}
+
+		for _, k := range menuKeys {
+			if strings.HasPrefix(s, k.seq) {
+				action = k.action
+				s = s[len(k.seq):]
+				break
+			}
+		}
+
+		switch action {
+		case keyUp:
+			sel -= 1
+		case keyDown:
+			sel += 1
+		case keyFirst:
+			sel = 0
+		case keyLast:
+			sel = len(m.Entries) - 1
+		case keyEnter:
+			return m.selected
+		case keyEscape:
+			return -1
+		default:
+			s = s[1:]
+		}
+
+		m.selectEntry(max(0, min(sel, len(m.Entries)-1)))
+		sel = m.selected
+	}
+
+	return -2
+}
+
+// pointer handles pointer input, it returns the clicked entry index (or -1)
+// and whether the pointer state changed.
+func (m *Menu) pointer(mode *uefi.PointerMode) (index int, input bool) {
+	state := &uefi.PointerState{}
+
+	if changed, err := m.Pointer.GetState(state); err != nil || !changed {
+		return -1, false
+	}
+
+	m.hidePointer()
+
+	m.px = max(0, min(m.px+movement(state.RelativeMovementX, mode.ResolutionX), m.width-1))
+	m.py = max(0, min(m.py+movement(state.RelativeMovementY, mode.ResolutionY), m.height-1))
+
+	i := m.entryAt(m.px, m.py)
+
+	if i >= 0 {
+		m.selectEntry(i)
+	}
+
+	m.showPointer()
+
+	if i >= 0 && state.LeftButton != 0 {
+		return i, true
+	}
+
+	return -1, true
+}
+
+// movement converts pointer counts to pixels.
+func movement(n int32, resolution uint64) int {
+	if resolution == 0 {
+		return int(n)
+	}
+
+	return int(n) * pointerScale / int(resolution)
+}
+
+func (m *Menu) entryAt(x int, y int) int {
+	if x < m.x || x >= m.x+m.w || y < m.y {
+		return -1
+	}
+
+	if row := (y - m.y) / m.rowH; row < m.visible {
+		return m.first + row
+	}
+
+	return -1
+}
+
+func (m *Menu) selectEntry(i int) {
+	if i == m.selected {
+		return
+	}
+
+	prev := m.selected
+	m.selected = i
+
+	if m.scroll() {
+		m.drawEntries()
+		return
+	}
+
+	m.drawEntry(prev)
+	m.drawEntry(i)
+}
+
+// scroll adjusts the entries on screen to include the selected one, it
+// returns whether they changed.
+func (m *Menu) scroll() bool {
+	first := m.first
+
+	switch {
+	case m.selected < m.first:
+		m.first = m.selected
+	case m.selected >= m.first+m.visible:
+		m.first = m.selected - m.visible + 1
+	}
+
+	return m.first != first
+}
+
+func (m *Menu) stopCountdown() {
+	if m.left >= 0 && m.Timeout > 0 {
+		m.drawStatus("")
+	}
+
+	m.left = -1
+}
+
+// draw renders the whole menu.
+func (m *Menu) draw() (err error) {
+	ch := m.Font.Height * m.Scale
+	cw := m.Font.Width * m.Scale
+	iconH := 0
+	top := m.height / 2
+
+	if err = Fill(m.GOP, m.Background, 0, 0, m.width, m.height); err != nil {
+		return
+	}
+
+	if m.Splash != nil {
+		// the splash image is downscaled to the upper screen half
+		splash := fit(m.Splash, m.width, top)
+		b := splash.Bounds()
+		x := (m.width - b.Dx()) / 2
+		y := (top - b.Dy()) / 2
+
+		if err = Draw(m.GOP, splash, x, y); err != nil {
+			return
+		}
+
+		top = max(top, y+b.Dy()+ch)
+	}
+
+	m.w = 0
+	m.iconW = 0
+
+	for _, e := range m.Entries {
+		if e.Icon != nil {
+			m.iconW = max(m.iconW, e.Icon.Bounds().Dx()+cw)
+			iconH = max(iconH, e.Icon.Bounds().Dy())
+		}
+
+		m.w = max(m.w, len([]rune(e.Title))*cw)
+	}
+
+	m.w += m.iconW + 2*cw
+	m.rowH = max(ch, iconH) + ch/2
+	m.x = max(0, (m.width-m.w)/2)
+	m.y = max(0, min(top, m.height-m.rowH-2*ch))
+
+	// entries exceeding the screen are scrolled, leaving room for the
+	// status line
+	m.visible = max(1, min(len(m.Entries), (m.height-m.y-2*ch)/m.rowH))
+	m.first = 0
+	m.scroll()
+
+	m.drawEntries()
+
+	return
+}
+
+// drawEntries renders the entries on screen.
+func (m *Menu) drawEntries() {
+	for i := m.first; i < m.first+m.visible; i++ {
+		m.drawEntry(i)
+	}
+}
+
+func (m *Menu) drawEntry(i int) {
+	if i < m.first || i >= m.first+m.visible {
+		return
+	}
+
+	e := m.Entries[i]
+	ch := m.Font.Height * m.Scale
+	cw := m.Font.Width * m.Scale
+	y := m.y + (i-m.first)*m.rowH
+	bg := m.Background
+
+	if i == m.selected {
+		bg = m.Highlight
+	}
+
+	Fill(m.GOP, bg, m.x, y, m.w, m.rowH)
+
+	if e.Icon != nil {
+		b := e.Icon.Bounds()
+		Fill(m.GOP, bg, m.x+cw, y+(m.rowH-b.Dy())/2, b.Dx(), b.Dy())
+		Draw(m.GOP, e.Icon, m.x+cw, y+(m.rowH-b.Dy())/2)
+	}
+
+	DrawText(m.GOP, m.Font, m.Scale, e.Title, m.x+cw+m.iconW, y+(m.rowH-ch)/2, m.Foreground, bg)
+
+	if m.under != nil && m.py+len(arrow) >= y && m.py < y+m.rowH {
+		// pointer background was overwritten
+		m.under = nil
+		m.showPointer()
+	}
+}
+
+// drawStatus renders a status line below the entries.
+func (m *Menu) drawStatus(s string) {
+	ch := m.Font.Height * m.Scale
+	y := m.y + m.visible*m.rowH + ch
+
+	if y+ch > m.height {
+		return
+	}
+
+	Fill(m.GOP, m.Background, 0, y, m.width, ch)
+
+	cw := m.Font.Width * m.Scale
+	x := max(0, (m.width-len([]rune(s))*cw)/2)
+
+	DrawText(m.GOP, m.Font, m.Scale, s, x, y, m.Foreground, m.Background)
+}
+
+func (m *Menu) showPointer() {
+	var err error
+
+	w := min(arrowWidth, m.width-m.px)
+	h := min(len(arrow), m.height-m.py)
+
+	if m.under, err = Capture(m.GOP, m.px, m.py, w, h); err != nil {
+		m.under = nil
+		return
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w && x < len(arrow[y]); x++ {
+			switch arrow[y][x] {
+			case 'X':
+				img.SetRGBA(x, y, color.RGBA{0, 0, 0, 0xff})
+			case 'O':
+				img.SetRGBA(x, y, color.RGBA{0xff, 0xff, 0xff, 0xff})
+			}
+		}
+	}
+
+	Draw(m.GOP, img, m.px, m.py)
+}
+
+func (m *Menu) hidePointer() {
+	if m.under == nil {
+		return
+	}
+
+	Draw(m.GOP, m.under, m.px, m.py)
+	m.under = nil
+}
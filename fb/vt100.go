@@ -0,0 +1,94 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package fb
+
+import (
+	"unicode/utf8"
+
+	"github.com/usbarmory/go-boot/vt100"
+)
+
+// screen implements [vt100.Terminal] on the console cells, it must be used
+// with the console lock held.
+type screen Console
+
+// Text outputs UTF-8 text at the cursor position.
+func (s *screen) Text(p []byte) error {
+	c := (*Console)(s)
+
+	for _, b := range p {
+		if b < utf8.RuneSelf {
+			c.utf = nil
+			c.control(rune(b))
+			continue
+		}
+
+		c.utf = append(c.utf, b)
+
+		if r, size := utf8.DecodeRune(c.utf); r != utf8.RuneError || size > 1 {
+			c.utf = nil
+			c.put(r)
+		} else if utf8.FullRune(c.utf) {
+			c.utf = nil
+			c.put(utf8.RuneError)
+		}
+	}
+
+	return nil
+}
+
+// Cursor returns the cursor position and the screen size.
+func (s *screen) Cursor() (col int, row int, cols int, rows int, err error) {
+	return min(s.col, s.cols-1), s.row, s.cols, s.rows, nil
+}
+
+// SetCursor moves the cursor to a screen position.
+func (s *screen) SetCursor(col int, row int) error {
+	s.col = col
+	s.row = row
+
+	return nil
+}
+
+// ShowCursor shows or hides the cursor.
+func (s *screen) ShowCursor(visible bool) error {
+	s.hidden = !visible
+	return nil
+}
+
+// Erase blanks n cells of a screen row, from the argument column.
+func (s *screen) Erase(col int, row int, n int) error {
+	(*Console)(s).erase(row, col, min(col+n, s.cols))
+	return nil
+}
+
+// Clear clears the screen, preserving its content in history, or discards
+// the history when scrollback is true.
+func (s *screen) Clear(scrollback bool) error {
+	if scrollback {
+		s.lines = s.lines[len(s.lines)-s.rows:]
+	} else {
+		(*Console)(s).clearScreen()
+	}
+
+	return nil
+}
+
+// Attribute returns the current colors and the default ones.
+func (s *screen) Attribute() (current vt100.Attribute, reset vt100.Attribute, err error) {
+	current = vt100.Attribute{Foreground: s.fg, Background: s.bg}
+	reset = vt100.Attribute{Foreground: DefaultForeground, Background: DefaultBackground}
+
+	return
+}
+
+// SetAttribute sets the colors for subsequent text.
+func (s *screen) SetAttribute(a vt100.Attribute) error {
+	s.fg = a.Foreground
+	s.bg = a.Background
+
+	return nil
+}
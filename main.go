@@ -14,6 +14,7 @@ import (
 	"os"
 
 	"github.com/usbarmory/go-boot/cmd"
+	"github.com/usbarmory/go-boot/fb"
 	"github.com/usbarmory/go-boot/shell"
 	"github.com/usbarmory/go-boot/uefi"
 	"github.com/usbarmory/go-boot/uefi/x64"
@@ -28,28 +29,151 @@ func init() {
 	log.SetFlags(0)
 
 	logFile, _ := os.OpenFile(cmd.LogPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	log.SetOutput(io.MultiWriter(os.Stdout, logFile))
+	shell.Logs.Output = io.MultiWriter(os.Stdout, logFile)
+	log.SetOutput(shell.Logs)
+}
+
+// framebuffer initializes a graphical console on the EFI Graphics Output
+// Protocol, replacing the EFI Simple Text console as standard output.
+func framebuffer() (c *fb.Console, err error) {
+	c = &fb.Console{
+		Input: x64.UEFI.Console,
+	}
+
+	if c.GOP, err = x64.UEFI.Boot.GetGraphicsOutput(); err != nil {
+		return
+	}
+
+	if err = c.Init(); err != nil {
+		return
+	}
+
+	x64.UEFI.Console.EnableCursor(false)
+	x64.UEFI.Console.KeyFilter = func(k *uefi.KeyData) ([]byte, bool) {
+		if buf, ok := c.Keys(k); ok {
+			return buf, ok
+		}
+
+		return cmd.ConsoleKeys(k)
+	}
+
+	x64.Console.Out = 0
+	x64.Stdout = c.Printk
+
+	cmd.Framebuffer = c
+
+	return
+}
+
+// multiplexer initializes a console multiplexer on serial port and UEFI
+// console, mirroring standard output to all multiplexed consoles.
+func multiplexer() (mux *shell.Mux) {
+	mux = &shell.Mux{}
+
+	x64.UEFI.Console.EnableCursor(true)
+	x64.UEFI.Console.KeyFilter = cmd.ConsoleKeys
+
+	mux.Add("com1", x64.UART0)
+	mux.Add("text", x64.UEFI.Console)
+
+	// EFI serial ports might overlap with com1, hence start disabled
+	if devices, err := x64.UEFI.Boot.GetSerialIO(); err == nil {
+		for i, sio := range devices {
+			name := fmt.Sprintf("serial%d", i)
+
+			mux.Add(name, sio)
+			mux.Enable(name, false)
+		}
+	}
+
+	x64.Console.Out = 0
+	x64.Stdout = func(b byte) {
+		if b == '\n' {
+			mux.Write([]byte{'\r', b})
+		} else {
+			mux.Write([]byte{b})
+		}
+	}
+
+	cmd.Consoles = mux
+
+	return
 }
 
 func main() {
 	// disable UEFI watchdog
 	x64.UEFI.Boot.SetWatchdogTimer(0)
 
+	cmd.Console = Console
+
+	if cmd.Locked() {
+		cmd.EnforceLockdown()
+	} else if err := cmd.LoadSettings(); err != nil {
+		log.Printf("could not load settings, %v", err)
+	}
+
 	console := &shell.Interface{
-		Banner:  cmd.Banner,
-		Console: x64.UEFI.Console,
+		Banner:   cmd.Banner,
+		Console:  x64.UEFI.Console,
+		Lockdown: cmd.Locked(),
 	}
 
-	switch Console {
+	// the EFI System Partition is not trusted in lockdown mode
+	if !cmd.Locked() {
+		console.Startup = cmd.StartupScript()
+	}
+
+	if auth, err := cmd.Authenticator(); err != nil {
+		log.Printf("could not load password, %v", err)
+		console.Authenticate = func(string) bool { return false }
+	} else {
+		console.Authenticate = auth
+	}
+
+	// output redirection writes the EFI System Partition
+	if root, err := x64.UEFI.Root(); err == nil && !cmd.Locked() {
+		console.Root = root
+		console.HistoryPath = cmd.HistoryPath
+	}
+
+	switch cmd.Console {
 	case "COM1", "com1", "":
 		console.ReadWriter = x64.UART0
 		console.Start(true)
+	case "SERIAL", "serial":
+		sio, err := cmd.SerialDevice(0)
+
+		if err == nil {
+			console.ReadWriter = sio
+			console.Start(true)
+			break
+		}
+
+		log.Printf("could not initialize EFI serial console, %v", err)
+
+		console.ReadWriter = x64.UART0
+		console.Start(true)
+	case "ALL", "all":
+		console.ReadWriter = multiplexer()
+		console.Start(true)
+	case "FB", "fb":
+		c, err := framebuffer()
+
+		if err == nil {
+			console.ReadWriter = c
+			console.Start(true)
+			break
+		}
+
+		log.Printf("could not initialize framebuffer console, %v", err)
+		fallthrough
 	case "TEXT", "text":
 		console.Console.EnableCursor(true)
+		console.Console.KeyFilter = cmd.ConsoleKeys
 		console.Pagination = true
 
 		console.ReadWriter = x64.UEFI.Console
-		console.Start(false)
+		console.Start(true)
 	}
 
 	log.Print("exit")
@@ -0,0 +1,58 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"maps"
+	"strings"
+	"sync"
+)
+
+var aliases = struct {
+	sync.Mutex
+	m map[string]string
+}{
+	m: make(map[string]string),
+}
+
+// SetAlias defines a command alias, a command line starting with the alias
+// name has it replaced with the alias value.
+func SetAlias(name string, value string) {
+	aliases.Lock()
+	defer aliases.Unlock()
+
+	aliases.m[name] = value
+}
+
+// RemoveAlias removes a command alias.
+func RemoveAlias(name string) {
+	aliases.Lock()
+	defer aliases.Unlock()
+
+	delete(aliases.m, name)
+}
+
+// Aliases returns all defined command aliases.
+func Aliases() map[string]string {
+	aliases.Lock()
+	defer aliases.Unlock()
+
+	return maps.Clone(aliases.m)
+}
+
+// alias expands a command alias, aliases are not expanded recursively.
+func alias(line string) string {
+	aliases.Lock()
+	defer aliases.Unlock()
+
+	name, rest, _ := strings.Cut(line, " ")
+
+	if value, ok := aliases.m[name]; ok {
+		return strings.TrimSpace(value + " " + rest)
+	}
+
+	return line
+}
@@ -0,0 +1,42 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// PasswordPrompt represents the prompt for [Interface.Authenticate].
+var PasswordPrompt = "password: "
+
+// maximum delay after failed authentication attempts
+const maxAuthDelay = 30 * time.Second
+
+// authenticate reads the interface password until verified, failed attempts
+// are delayed with exponential backoff.
+func (c *Interface) authenticate() (err error) {
+	var password string
+
+	delay := time.Second
+
+	for {
+		if password, err = c.t.ReadPassword(PasswordPrompt); err != nil {
+			return
+		}
+
+		if c.Authenticate(password) {
+			return
+		}
+
+		log.Print("authentication failure")
+		fmt.Fprintln(c.Output, "invalid password")
+
+		time.Sleep(delay)
+		delay = min(delay*2, maxAuthDelay)
+	}
+}
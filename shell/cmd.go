@@ -7,9 +7,14 @@ package shell
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"regexp"
+	"slices"
 	"sort"
+	"sync"
 	"text/tabwriter"
 
 	"github.com/usbarmory/go-boot/uefi"
@@ -18,6 +23,10 @@ import (
 // CmdFn represents a command handler.
 type CmdFn func(c *Interface, arg []string) (res string, err error)
 
+// StreamFn represents a streaming command handler, output is written as it
+// is produced and the context is cancelled when Ctrl-C is pressed.
+type StreamFn func(ctx context.Context, c *Interface, w io.Writer, arg []string) (err error)
+
 // Cmd represents a shell command.
 type Cmd struct {
 	// Name is the command name.
@@ -30,6 +39,10 @@ type Cmd struct {
 	// Pattern defines the command syntax and arguments.
 	Pattern *regexp.Regexp
 
+	// Spec defines the command arguments declaratively, when set Args,
+	// Pattern and (if empty) Syntax are derived from it.
+	Spec []Arg
+
 	// Syntax defines the Help() command syntax field.
 	Syntax string
 
@@ -38,31 +51,272 @@ type Cmd struct {
 
 	// Fn defines the command handler.
 	Fn CmdFn
+
+	// Stream defines the streaming command handler, used in place of Fn
+	// when set.
+	Stream StreamFn
+
+	// Data defines the structured output handler, used in place of Fn and
+	// Stream in JSON output mode when set.
+	Data DataFn
+}
+
+// Registry represents a set of terminal interface commands, it is safe for
+// concurrent use.
+type Registry struct {
+	sync.RWMutex
+
+	cmds map[string]*Cmd
+}
+
+// DefaultCommands represents the command set of interfaces with no Commands
+// set, commands registered with [Add] belong to it.
+var DefaultCommands = &Registry{}
+
+// Add registers a terminal interface command, it panics if the command Spec
+// is invalid, if any of its name aliases is already registered by a
+// different command or if it matches the same input as a command with equal
+// precedence.
+func (r *Registry) Add(cmd Cmd) {
+	if len(cmd.Spec) > 0 {
+		if err := cmd.compile(); err != nil {
+			panic(fmt.Sprintf("shell: invalid command %s, %v", cmd.Name, err))
+		}
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.cmds == nil {
+		r.cmds = make(map[string]*Cmd)
+	}
+
+	for _, c := range r.cmds {
+		if c.Name == cmd.Name {
+			continue
+		}
+
+		for _, alias := range c.aliases() {
+			if slices.Contains(cmd.aliases(), alias) {
+				panic(fmt.Sprintf("shell: ambiguous command %s, alias %s already registered by %s", cmd.Name, alias, c.Name))
+			}
+		}
+
+		if cmd.overlaps(c) || c.overlaps(&cmd) {
+			panic(fmt.Sprintf("shell: ambiguous command %s, input matched by %s", cmd.Name, c.Name))
+		}
+	}
+
+	r.cmds[cmd.Name] = &cmd
+}
+
+// Remove unregisters the commands matching any of the argument names or
+// aliases.
+func (r *Registry) Remove(names ...string) {
+	r.Lock()
+	defer r.Unlock()
+
+	for name, cmd := range r.cmds {
+		for _, alias := range cmd.aliases() {
+			if slices.Contains(names, alias) || slices.Contains(names, name) {
+				delete(r.cmds, name)
+				break
+			}
+		}
+	}
+}
+
+// Without returns a copy of the registry excluding the commands matching any
+// of the argument names or aliases (e.g. to restrict remote sessions).
+func (r *Registry) Without(names ...string) *Registry {
+	c := &Registry{
+		cmds: make(map[string]*Cmd),
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	for name, cmd := range r.cmds {
+		c.cmds[name] = cmd
+	}
+
+	c.Remove(names...)
+
+	return c
+}
+
+// Only returns a copy of the registry including only the commands matching
+// any of the argument names or aliases (e.g. to restrict remote sessions).
+func (r *Registry) Only(names ...string) *Registry {
+	c := &Registry{
+		cmds: make(map[string]*Cmd),
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	for name, cmd := range r.cmds {
+		for _, alias := range cmd.aliases() {
+			if slices.Contains(names, alias) || slices.Contains(names, name) {
+				c.cmds[name] = cmd
+				break
+			}
+		}
+	}
+
+	return c
+}
+
+// lookup returns the command matching the argument name or alias.
+func (r *Registry) lookup(name string) *Cmd {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, cmd := range r.cmds {
+		if cmd.Name == name || slices.Contains(cmd.aliases(), name) {
+			return cmd
+		}
+	}
+
+	return nil
 }
 
-var cmds = make(map[string]*Cmd)
+// list returns the registered commands sorted by name.
+func (r *Registry) list() (list []*Cmd) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, cmd := range r.cmds {
+		list = append(list, cmd)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
 
-// Add registers a terminal interface command.
+	return
+}
+
+// Add registers a terminal interface command in [DefaultCommands].
 func Add(cmd Cmd) {
-	cmds[cmd.Name] = &cmd
+	DefaultCommands.Add(cmd)
+}
+
+// equal returns whether two commands have the same dispatch precedence.
+func (cmd *Cmd) equal(c *Cmd) bool {
+	return (cmd.Pattern == nil) == (c.Pattern == nil) && cmd.literals() == c.literals()
+}
+
+// overlaps returns whether a command Pattern matches any name alias of a
+// command with equal precedence, making dispatch between them ambiguous.
+func (cmd *Cmd) overlaps(c *Cmd) bool {
+	if cmd.Pattern == nil || !cmd.equal(c) {
+		return false
+	}
+
+	for _, alias := range c.aliases() {
+		if alias == enterAlias {
+			alias = ""
+		}
+
+		if m := cmd.Pattern.FindStringSubmatch(alias); len(m) > 0 && len(m)-1 == cmd.Args {
+			return true
+		}
+	}
+
+	return false
+}
+
+// precedence returns the registered commands in dispatch order, commands
+// without Pattern (exact match) come first followed by those with the most
+// literal name tokens (e.g. `acpi dump` before `acpi`).
+func (r *Registry) precedence() (list []*Cmd) {
+	list = r.list()
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a := list[i]
+		b := list[j]
+
+		switch {
+		case (a.Pattern == nil) != (b.Pattern == nil):
+			return a.Pattern == nil
+		default:
+			return a.literals() > b.literals()
+		}
+	})
+
+	return
+}
+
+// match returns the command matching the argument line and its arguments,
+// an error is returned when multiple commands with the same precedence
+// match.
+func (r *Registry) match(line string) (match *Cmd, arg []string, err error) {
+	for _, cmd := range r.precedence() {
+		var m []string
+
+		if cmd.Pattern == nil {
+			if !slices.Contains(cmd.aliases(), line) {
+				continue
+			}
+		} else if m = cmd.Pattern.FindStringSubmatch(line); len(m) == 0 || len(m)-1 != cmd.Args {
+			continue
+		}
+
+		if match != nil {
+			if match.equal(cmd) {
+				return nil, nil, fmt.Errorf("ambiguous command, matches %s and %s", match.Name, cmd.Name)
+			}
+
+			break
+		}
+
+		match = cmd
+
+		if len(m) > 0 {
+			arg = cmd.values(m[1:])
+		}
+	}
+
+	if match == nil {
+		return nil, nil, errors.New("unknown command, type `help`")
+	}
+
+	return
 }
 
 // Help returns a formatted string with instructions for all registered
-// commands.
-func Help(c *Interface, _ []string) (_ string, _ error) {
+// commands, or the help page of the command passed as argument.
+func Help(c *Interface, arg []string) (_ string, _ error) {
 	var help bytes.Buffer
 	var names []string
 
+	if len(arg) > 0 && len(arg[0]) > 0 {
+		if cmd := c.commands().lookup(arg[0]); cmd != nil {
+			return cmd.Usage(), nil
+		}
+
+		if f, ok := filters[arg[0]]; ok {
+			return fmt.Sprintf("| %s %s\n\n  %s\n", f.Name, f.Syntax, f.Help), nil
+		}
+
+		return "", fmt.Errorf("unknown command %s", arg[0])
+	}
+
 	t := tabwriter.NewWriter(&help, 16, 8, 0, ' ', tabwriter.TabIndent)
 
-	for name, _ := range cmds {
+	for _, cmd := range c.commands().list() {
+		_, _ = fmt.Fprintf(t, "%s\t%s\t # %s\n", cmd.Name, cmd.Syntax, cmd.Help)
+	}
+
+	for name, _ := range filters {
 		names = append(names, name)
 	}
 
 	sort.Strings(names)
 
 	for _, name := range names {
-		_, _ = fmt.Fprintf(t, "%s\t%s\t # %s\n", cmds[name].Name, cmds[name].Syntax, cmds[name].Help)
+		_, _ = fmt.Fprintf(t, "| %s\t%s\t # %s\n", filters[name].Name, filters[name].Syntax, filters[name].Help)
 	}
 
 	_ = t.Flush()
@@ -0,0 +1,171 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+const tab = '\t'
+
+// commandNames returns all registered command and filter names, including
+// aliases.
+func (c *Interface) commandNames(filter bool) (names []string) {
+	if filter {
+		for name := range filters {
+			names = append(names, name)
+		}
+
+		return
+	}
+
+	for name := range Aliases() {
+		names = append(names, name)
+	}
+
+	for _, cmd := range c.commands().list() {
+		for name := range strings.SplitSeq(cmd.Name, ",") {
+			if name = strings.TrimSpace(name); (len(name) > 1 && !strings.HasPrefix(name, `\`)) || name == "." {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return
+}
+
+// paths returns the root volume entries matching the argument path prefix.
+func (c *Interface) paths(prefix string) (matches []string) {
+	if c.Root == nil {
+		return
+	}
+
+	sep := `\`
+
+	if strings.Contains(prefix, "/") {
+		sep = "/"
+	}
+
+	dir := ""
+	base := prefix
+
+	if i := strings.LastIndexAny(prefix, `\/`); i >= 0 {
+		dir = prefix[:i+1]
+		base = prefix[i+1:]
+	}
+
+	p := strings.ReplaceAll(dir, `\`, `/`)
+
+	if len(p) == 0 {
+		p = "."
+	}
+
+	entries, err := fs.ReadDir(c.Root, p)
+
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+
+		if name == "." || name == ".." || !strings.HasPrefix(strings.ToLower(name), strings.ToLower(base)) {
+			continue
+		}
+
+		if e.IsDir() {
+			name += sep
+		}
+
+		matches = append(matches, dir+name)
+	}
+
+	return
+}
+
+// commonPrefix returns the longest common prefix among strings, compared
+// case-insensitively as path candidates are matched.
+func commonPrefix(s []string) (prefix string) {
+	if len(s) == 0 {
+		return
+	}
+
+	prefix = s[0]
+
+	for _, v := range s[1:] {
+		n := min(len(prefix), len(v))
+
+		for n > 0 && !strings.EqualFold(prefix[:n], v[:n]) {
+			n -= 1
+		}
+
+		prefix = prefix[:n]
+	}
+
+	return
+}
+
+// complete implements term.Terminal.AutoCompleteCallback for command names
+// and root volume paths.
+func (c *Interface) complete(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
+	var candidates []string
+
+	if key != tab {
+		return
+	}
+
+	prefix := line[:pos]
+	start := 0
+	filter := false
+
+	if i := strings.LastIndex(prefix, "| "); i >= 0 {
+		start = i + 2
+		filter = true
+	}
+
+	word := prefix[start:]
+
+	// complete command names, which might include spaces, first
+	for _, name := range c.commandNames(filter) {
+		if strings.HasPrefix(name, word) {
+			candidates = append(candidates, name)
+		}
+	}
+
+	// complete paths for command arguments
+	if len(candidates) == 0 && strings.Contains(word, " ") {
+		start += strings.LastIndex(word, " ") + 1
+		candidates = c.paths(prefix[start:])
+	}
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	sort.Strings(candidates)
+	completion := commonPrefix(candidates)
+
+	// never shorten the typed word
+	if typed := prefix[start:]; len(completion) < len(typed) {
+		completion = typed
+	}
+
+	switch {
+	case len(candidates) == 1 && !strings.HasSuffix(completion, `\`) && !strings.HasSuffix(completion, "/"):
+		completion += " "
+	case len(completion) <= len(prefix[start:]):
+		// ambiguous completion, show candidates
+		fmt.Fprintf(c.t, "%s\n", strings.Join(candidates, "  "))
+	}
+
+	newLine = line[:start] + completion + line[pos:]
+	newPos = start + len(completion)
+
+	return newLine, newPos, true
+}
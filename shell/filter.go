@@ -0,0 +1,216 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// default number of lines for head and tail filters
+const defaultLines = 10
+
+// FilterFn represents a pipeline filter handler, it receives the previous
+// pipeline stage output.
+type FilterFn func(in string, arg []string) (out string, err error)
+
+// Filter represents a shell pipeline filter, invoked as `<command> | <filter>`.
+type Filter struct {
+	// Name is the filter name.
+	Name string
+
+	// Args defines the number of filter arguments, meant to be in the
+	// Pattern capturing brackets.
+	Args int
+
+	// Pattern defines the filter syntax and arguments.
+	Pattern *regexp.Regexp
+
+	// Syntax defines the Help() filter syntax field.
+	Syntax string
+
+	// Help defines the Help() filter description field.
+	Help string
+
+	// Fn defines the filter handler.
+	Fn FilterFn
+}
+
+var filters = map[string]*Filter{
+	"grep": {
+		Name:    "grep",
+		Args:    2,
+		Pattern: regexp.MustCompile(`^grep((?: -[iv])*) (.+)$`),
+		Syntax:  "(-i)? (-v)? <regexp>",
+		Help:    "filter lines matching pattern",
+		Fn:      grepFilter,
+	},
+	"head": {
+		Name:    "head",
+		Args:    1,
+		Pattern: regexp.MustCompile(`^head(?: -n)?(?: (\d+))?$`),
+		Syntax:  "(<lines>)?",
+		Help:    "show first lines",
+		Fn:      headFilter,
+	},
+	"tail": {
+		Name:    "tail",
+		Args:    1,
+		Pattern: regexp.MustCompile(`^tail(?: -n)?(?: (\d+))?$`),
+		Syntax:  "(<lines>)?",
+		Help:    "show last lines",
+		Fn:      tailFilter,
+	},
+	"wc": {
+		Name: "wc",
+		Help: "count lines, words and bytes",
+		Fn:   wcFilter,
+	},
+	"hexdump": {
+		Name: "hexdump",
+		Help: "hexadecimal dump",
+		Fn:   hexdumpFilter,
+	},
+}
+
+// AddFilter registers a pipeline filter, its name is the first token of the
+// pipeline stage.
+func AddFilter(f Filter) {
+	filters[f.Name] = &f
+}
+
+func lines(s string) (l []string) {
+	for line := range strings.Lines(s) {
+		l = append(l, line)
+	}
+
+	return
+}
+
+func count(arg string) int {
+	if n, err := strconv.Atoi(arg); err == nil {
+		return n
+	}
+
+	return defaultLines
+}
+
+func grepFilter(in string, arg []string) (out string, err error) {
+	var buf strings.Builder
+
+	expr := arg[1]
+	invert := strings.Contains(arg[0], "-v")
+
+	if strings.Contains(arg[0], "-i") {
+		expr = "(?i)" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern, %v", err)
+	}
+
+	for line := range strings.Lines(in) {
+		if re.MatchString(strings.TrimSuffix(line, "\n")) != invert {
+			buf.WriteString(line)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+func headFilter(in string, arg []string) (out string, err error) {
+	l := lines(in)
+	return strings.Join(l[:min(count(arg[0]), len(l))], ""), nil
+}
+
+func tailFilter(in string, arg []string) (out string, err error) {
+	l := lines(in)
+	return strings.Join(l[max(0, len(l)-count(arg[0])):], ""), nil
+}
+
+func wcFilter(in string, _ []string) (out string, err error) {
+	return fmt.Sprintf("%d %d %d", len(lines(in)), len(strings.Fields(in)), len(in)), nil
+}
+
+func hexdumpFilter(in string, _ []string) (out string, err error) {
+	return hex.Dump([]byte(in)), nil
+}
+
+// filter applies a pipeline filter to the argument input.
+func filter(in string, line string) (out string, err error) {
+	name, _, _ := strings.Cut(line, " ")
+	f, ok := filters[name]
+
+	switch {
+	case !ok:
+		return "", fmt.Errorf("unknown filter %s", name)
+	case f.Pattern == nil && f.Name == line:
+		return f.Fn(in, nil)
+	case f.Pattern != nil:
+		if m := f.Pattern.FindStringSubmatch(line); len(m) > 0 && (len(m)-1 == f.Args) {
+			return f.Fn(in, m[1:])
+		}
+	}
+
+	return "", fmt.Errorf("invalid filter syntax, %s %s", f.Name, f.Syntax)
+}
+
+// redirection parses a trailing `> <path>` or `>> <path>` output redirection.
+var redirection = regexp.MustCompile(`^(.*?)\s+(>>?)\s*(\S+)$`)
+
+// redirect writes command output to a file on the interface root volume.
+func (c *Interface) redirect(name string, flag int, res string) (err error) {
+	if c.Root == nil {
+		return errors.New("output redirection not available")
+	}
+
+	f, err := c.Root.OpenFile(name, os.O_WRONLY|os.O_CREATE|flag)
+
+	if err != nil {
+		return fmt.Errorf("could not open %s, %v", name, err)
+	}
+
+	if len(res) > 0 && !strings.HasSuffix(res, "\n") {
+		res += "\n"
+	}
+
+	if _, err = f.Write([]byte(res)); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write %s, %v", name, err)
+	}
+
+	return f.Close()
+}
+
+// pipeline splits a command line in its command, filters and optional
+// output redirection.
+func pipeline(line string) (cmd string, stages []string, name string, flag int) {
+	if m := redirection.FindStringSubmatch(line); len(m) > 0 && len(m[1]) > 0 {
+		line = m[1]
+		name = m[3]
+
+		if m[2] == ">>" {
+			flag = os.O_APPEND
+		} else {
+			flag = os.O_TRUNC
+		}
+	}
+
+	stages = strings.Split(line, " | ")
+
+	for i := range stages {
+		stages[i] = strings.TrimSpace(stages[i])
+	}
+
+	return stages[0], stages[1:], name, flag
+}
@@ -0,0 +1,104 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/usbarmory/go-boot/uefi"
+)
+
+// HistorySize represents the maximum number of command history entries.
+var HistorySize = 100
+
+// history implements term.History, optionally persisting entries on a file.
+type history struct {
+	// entries in least-recent first order
+	entries []string
+
+	root *uefi.FS
+	path string
+}
+
+func (h *history) load() {
+	if h.root == nil {
+		return
+	}
+
+	buf, err := fs.ReadFile(h.root, strings.ReplaceAll(h.path, `\`, `/`))
+
+	if err != nil {
+		return
+	}
+
+	for line := range strings.Lines(string(buf)) {
+		if line = strings.TrimRight(line, "\r\n"); len(line) > 0 {
+			h.entries = append(h.entries, line)
+		}
+	}
+
+	if len(h.entries) > HistorySize {
+		// entries are only appended on save, trim them once per session
+		h.entries = h.entries[len(h.entries)-HistorySize:]
+		h.root.WriteFile(h.path, []byte(strings.Join(h.entries, "\n")+"\n"))
+	}
+}
+
+// save appends an entry to the history file.
+func (h *history) save(entry string) {
+	if h.root == nil {
+		return
+	}
+
+	f, err := h.root.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+
+	if err != nil {
+		return
+	}
+
+	f.Write([]byte(entry + "\n"))
+	f.Close()
+}
+
+// Add adds a new, most recent entry to the history.
+func (h *history) Add(entry string) {
+	if len(entry) == 0 || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	h.entries = h.entries[max(0, len(h.entries)-HistorySize):]
+
+	h.save(entry)
+}
+
+// Len returns the number of entries in the history.
+func (h *history) Len() int {
+	return len(h.entries)
+}
+
+// At returns an entry from the history, index 0 is the most recent entry.
+func (h *history) At(i int) string {
+	return h.entries[len(h.entries)-1-i]
+}
+
+// noHistory implements term.History without recording any entry.
+type noHistory struct{}
+
+// Add discards the entry.
+func (noHistory) Add(string) {}
+
+// Len returns zero.
+func (noHistory) Len() int {
+	return 0
+}
+
+// At is never called as the history is empty.
+func (noHistory) At(int) string {
+	return ""
+}
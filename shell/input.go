@@ -0,0 +1,217 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// ASCII control characters
+const (
+	ctrlC = 0x03 // interrupt
+	ctrlE = 0x05 // end of line
+	ctrlH = 0x08 // backspace
+	ctrlU = 0x15 // erase line
+	esc   = 0x1b // escape sequence
+	del   = 0x7f // backspace
+)
+
+// escape sequence parsing states
+const (
+	escNone = iota
+	escStart
+	escCSI
+)
+
+// input reads the interface connection in the background, allowing Ctrl-C
+// detection while commands are running. When no command is running Ctrl-C
+// discards the current line.
+type input struct {
+	sync.Mutex
+
+	r      io.Reader
+	ch     chan []byte
+	err    error
+	buf    []byte
+	cancel context.CancelFunc
+
+	// restrict input to printable characters, enter, backspace and
+	// line discard
+	lockdown bool
+	esc      int
+}
+
+func newInput(r io.Reader, lockdown bool) (in *input) {
+	in = &input{
+		r:        r,
+		ch:       make(chan []byte, 16),
+		lockdown: lockdown,
+	}
+
+	go in.read()
+
+	return
+}
+
+func (in *input) read() {
+	buf := make([]byte, 64)
+
+	for {
+		n, err := in.r.Read(buf)
+
+		if n > 0 {
+			if p := in.filter(buf[:n]); len(p) > 0 {
+				in.ch <- p
+			}
+		}
+
+		if err != nil {
+			in.err = err
+			close(in.ch)
+			return
+		}
+
+		if n == 0 {
+			time.Sleep(PollInterval)
+		}
+	}
+}
+
+// filter handles Ctrl-C by cancelling the running command, if any, or by
+// discarding the current line.
+func (in *input) filter(buf []byte) (p []byte) {
+	in.Lock()
+	defer in.Unlock()
+
+	for _, b := range buf {
+		if b == ctrlC {
+			in.esc = escNone
+
+			if in.cancel != nil {
+				in.cancel()
+			} else {
+				p = append(p, ctrlE, ctrlU)
+			}
+
+			continue
+		}
+
+		// line editing restrictions also apply while commands are
+		// running, as input is buffered until they return
+		if in.lockdown && !in.allowed(b) {
+			continue
+		}
+
+		p = append(p, b)
+	}
+
+	return
+}
+
+// allowed returns whether an input byte is allowed in lockdown mode, escape
+// sequences (e.g. cursor keys) and line editing control characters are
+// discarded.
+func (in *input) allowed(b byte) bool {
+	switch in.esc {
+	case escStart:
+		if b == '[' || b == 'O' {
+			in.esc = escCSI
+		} else {
+			in.esc = escNone
+		}
+
+		return false
+	case escCSI:
+		// final byte
+		if b >= 0x40 && b <= 0x7e {
+			in.esc = escNone
+		}
+
+		return false
+	}
+
+	switch b {
+	case esc:
+		in.esc = escStart
+		return false
+	case '\r', '\n', ctrlE, ctrlH, ctrlU, del:
+		return true
+	default:
+		return b >= 0x20
+	}
+}
+
+// setCancel sets the function invoked on Ctrl-C, it returns false if one is
+// already set.
+func (in *input) setCancel(cancel context.CancelFunc) bool {
+	in.Lock()
+	defer in.Unlock()
+
+	if cancel != nil && in.cancel != nil {
+		return false
+	}
+
+	in.cancel = cancel
+
+	return true
+}
+
+// Read reads buffered input, blocking until available.
+func (in *input) Read(p []byte) (n int, err error) {
+	if len(in.buf) == 0 {
+		var ok bool
+
+		if in.buf, ok = <-in.ch; !ok {
+			return 0, in.err
+		}
+	}
+
+	n = copy(p, in.buf)
+	in.buf = in.buf[n:]
+
+	return
+}
+
+// interruptible returns a context cancelled on Ctrl-C, nested invocations
+// derive from the outermost one, which is the one cancelled. The returned
+// function must be called to release the context.
+func (c *Interface) interruptible() (ctx context.Context, done func()) {
+	prev := c.ctx
+	parent := prev
+
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	c.ctx = ctx
+
+	outer := c.in != nil && c.in.setCancel(cancel)
+
+	return ctx, func() {
+		if outer {
+			c.in.setCancel(nil)
+		}
+
+		cancel()
+		c.ctx = prev
+	}
+}
+
+// Input returns the interface connection input, for commands which handle
+// key strokes directly (e.g. a boot menu) as the connection is read in the
+// background. The channel is closed when the connection is terminated, nil
+// is returned for interfaces driven by [Interface.Terminal].
+func (c *Interface) Input() <-chan []byte {
+	if c.in == nil {
+		return nil
+	}
+
+	return c.in.ch
+}
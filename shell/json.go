@@ -0,0 +1,133 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSON output mode command prefix and suffix
+const (
+	jsonPrefix = "json "
+	jsonSuffix = " --json"
+)
+
+// DataFn represents a structured output command handler, its result is
+// returned as JSON in JSON output mode.
+type DataFn func(c *Interface, arg []string) (v any, err error)
+
+// jsonResult represents a command result in JSON output mode.
+type jsonResult struct {
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// reported represents a command error already returned as JSON.
+type reported struct {
+	error
+}
+
+// isReported returns whether an error has already been returned as JSON.
+func isReported(err error) bool {
+	var r reported
+	return errors.As(err, &r)
+}
+
+// jsonMode strips the JSON output mode prefix or suffix from a command line,
+// it returns whether JSON output mode applies to it.
+func (c *Interface) jsonMode(line string) (string, bool) {
+	if l, ok := strings.CutPrefix(line, jsonPrefix); ok && l != "on" && l != "off" {
+		return l, true
+	}
+
+	if l, ok := strings.CutSuffix(line, jsonSuffix); ok {
+		return l, true
+	}
+
+	return line, c.JSON
+}
+
+// data executes a command returning its structured output, when available,
+// or its text output otherwise.
+func (c *Interface) data(line string) (v any, err error) {
+	cmd, arg, err := c.commands().match(line)
+
+	if err != nil {
+		return
+	}
+
+	ctx, done := c.interruptible()
+	defer done()
+
+	switch {
+	case cmd.Data != nil:
+		return cmd.Data(c, arg)
+	case cmd.Stream != nil:
+		var buf bytes.Buffer
+		err = cmd.Stream(ctx, c, &buf, arg)
+		return buf.String(), err
+	default:
+		return cmd.Fn(c, arg)
+	}
+}
+
+// output returns a command result, filtered through the argument pipeline
+// stages, as a JSON object.
+func (c *Interface) output(line string, stages []string) (out string, err error) {
+	var res jsonResult
+	var buf []byte
+
+	if res.Result, err = c.data(line); err != nil {
+		return
+	}
+
+	if buf, err = json.Marshal(&res); err != nil {
+		return "", fmt.Errorf("could not encode result, %v", err)
+	}
+
+	out = string(buf)
+
+	for _, stage := range stages {
+		if out, err = filter(out, stage); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// handleJSON executes a command in JSON output mode, results and errors are
+// written as a single line JSON object.
+func (c *Interface) handleJSON(line string, stages []string, name string, flag int) (err error) {
+	out, err := c.output(line, stages)
+
+	switch {
+	case err == io.EOF:
+		return
+	case err == nil && len(name) > 0:
+		if err = c.redirect(name, flag, out); err == nil {
+			return
+		}
+	case err == nil:
+		fmt.Fprintln(c.Output, out)
+		return
+	}
+
+	buf, _ := json.Marshal(&jsonResult{Error: err.Error()})
+	fmt.Fprintln(c.Output, string(buf))
+
+	return reported{err}
+}
+
+func jsonCmd(c *Interface, arg []string) (res string, err error) {
+	c.JSON = arg[0] == "on"
+	return
+}
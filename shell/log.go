@@ -0,0 +1,72 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// LogWriter represents a log output fan-out, interface sessions attach their
+// own sink to it rather than replacing the standard logger output.
+type LogWriter struct {
+	sync.Mutex
+
+	// Output represents the main log output.
+	Output io.Writer
+
+	sinks []*logSink
+}
+
+type logSink struct {
+	w io.Writer
+}
+
+// Logs represents the log output fan-out of interfaces with a LogSink, it
+// is meant to be set as standard logger output (e.g. `log.SetOutput(Logs)`).
+var Logs = &LogWriter{
+	Output: os.Stdout,
+}
+
+// Attach adds a sink to the log output, the returned function removes it.
+func (l *LogWriter) Attach(w io.Writer) (detach func()) {
+	l.Lock()
+	defer l.Unlock()
+
+	sink := &logSink{w}
+	l.sinks = append(l.sinks, sink)
+
+	return func() {
+		l.Lock()
+		defer l.Unlock()
+
+		for i, s := range l.sinks {
+			if s == sink {
+				l.sinks = append(l.sinks[:i], l.sinks[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Write writes to the main log output and all attached sinks, sink errors
+// are ignored.
+func (l *LogWriter) Write(p []byte) (n int, err error) {
+	l.Lock()
+	sinks := append([]*logSink{}, l.sinks...)
+	l.Unlock()
+
+	for _, s := range sinks {
+		s.w.Write(p)
+	}
+
+	if l.Output == nil {
+		return len(p), nil
+	}
+
+	return l.Output.Write(p)
+}
@@ -0,0 +1,273 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// PollInterval represents the polling interval for consoles which do not
+// block on Read() when no input is available.
+var PollInterval = 10 * time.Millisecond
+
+type muxConsole struct {
+	name    string
+	rw      io.ReadWriter
+	enabled bool
+}
+
+// Mux represents a console multiplexer, input is read from all enabled
+// consoles and output is mirrored to all of them.
+type Mux struct {
+	sync.Mutex
+
+	consoles []*muxConsole
+	input    chan []byte
+	pending  []byte
+	// signaled on console state changes
+	changed *sync.Cond
+}
+
+func (m *Mux) init() {
+	if m.input == nil {
+		m.input = make(chan []byte, 16)
+	}
+
+	if m.changed == nil {
+		m.changed = sync.NewCond(&m.Mutex)
+	}
+}
+
+func (m *Mux) find(name string) (i int, c *muxConsole) {
+	for i, c = range m.consoles {
+		if c.name == name {
+			return
+		}
+	}
+
+	return -1, nil
+}
+
+// Add adds an enabled console to the multiplexer, its input is read until
+// an error is returned or the console is removed.
+func (m *Mux) Add(name string, rw io.ReadWriter) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.init()
+
+	if _, c := m.find(name); c != nil {
+		return fmt.Errorf("console %s already present", name)
+	}
+
+	c := &muxConsole{
+		name:    name,
+		rw:      rw,
+		enabled: true,
+	}
+
+	m.consoles = append(m.consoles, c)
+
+	go m.read(c)
+
+	return nil
+}
+
+// Remove removes a console from the multiplexer.
+func (m *Mux) Remove(name string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.init()
+
+	if i, _ := m.find(name); i >= 0 {
+		m.consoles = append(m.consoles[:i], m.consoles[i+1:]...)
+		m.changed.Broadcast()
+	}
+}
+
+// Enable enables or disables console input and output.
+func (m *Mux) Enable(name string, enabled bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	_, c := m.find(name)
+
+	if c == nil {
+		return fmt.Errorf("console %s not found", name)
+	}
+
+	if !enabled {
+		n := 0
+
+		for _, c := range m.consoles {
+			if c.enabled {
+				n += 1
+			}
+		}
+
+		if n == 1 && c.enabled {
+			return errors.New("cannot disable last enabled console")
+		}
+	}
+
+	c.enabled = enabled
+
+	m.init()
+	m.changed.Broadcast()
+
+	return nil
+}
+
+// String returns the multiplexed consoles and their state.
+func (m *Mux) String() string {
+	var buf bytes.Buffer
+
+	m.Lock()
+	defer m.Unlock()
+
+	for _, c := range m.consoles {
+		state := "off"
+
+		if c.enabled {
+			state = "on"
+		}
+
+		fmt.Fprintf(&buf, "%-16s %s\n", c.name, state)
+	}
+
+	return buf.String()
+}
+
+// state returns whether a console is still multiplexed and enabled.
+func (m *Mux) state(c *muxConsole) (present bool, enabled bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	_, found := m.find(c.name)
+
+	return found == c, found == c && c.enabled
+}
+
+// wait blocks until a console is enabled, it returns false once the console
+// is no longer multiplexed.
+func (m *Mux) wait(c *muxConsole) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	for {
+		if _, found := m.find(c.name); found != c {
+			return false
+		}
+
+		if c.enabled {
+			return true
+		}
+
+		m.changed.Wait()
+	}
+}
+
+func (m *Mux) read(c *muxConsole) {
+	buf := make([]byte, 64)
+
+	for {
+		// disabled consoles are not read, leaving their input to
+		// other readers
+		if !m.wait(c) {
+			return
+		}
+
+		n, err := c.rw.Read(buf)
+		present, enabled := m.state(c)
+
+		if n > 0 && enabled {
+			m.input <- bytes.Clone(buf[:n])
+		}
+
+		if err != nil {
+			if present {
+				m.Remove(c.name)
+			}
+
+			return
+		}
+
+		if !present {
+			return
+		}
+
+		if n == 0 {
+			time.Sleep(PollInterval)
+		}
+	}
+}
+
+// Read reads input from any enabled console, blocking until input is
+// available.
+func (m *Mux) Read(p []byte) (n int, err error) {
+	m.Lock()
+	m.init()
+	m.Unlock()
+
+	if len(m.pending) == 0 {
+		m.pending = <-m.input
+	}
+
+	n = copy(p, m.pending)
+	m.pending = m.pending[n:]
+
+	return
+}
+
+// Write writes output to all enabled consoles, consoles returning an error
+// are removed.
+func (m *Mux) Write(p []byte) (n int, err error) {
+	m.Lock()
+	consoles := append([]*muxConsole{}, m.consoles...)
+	m.Unlock()
+
+	for _, c := range consoles {
+		if _, enabled := m.state(c); !enabled {
+			continue
+		}
+
+		if _, err := c.rw.Write(p); err != nil {
+			m.Remove(c.name)
+		}
+	}
+
+	return len(p), nil
+}
+
+// Size returns the smallest size among enabled consoles which report one.
+func (m *Mux) Size() (cols int, rows int) {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, c := range m.consoles {
+		if !c.enabled {
+			continue
+		}
+
+		if w, h := consoleSize(c.rw); w > 0 {
+			if cols == 0 || w < cols {
+				cols = w
+			}
+
+			if rows == 0 || h < rows {
+				rows = h
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,236 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// maximum nesting of scripts executed from scripts
+const maxScriptDepth = 16
+
+// script statement types
+const (
+	stmtCmd = iota
+	stmtIf
+	stmtFor
+)
+
+var (
+	assignment = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+	variable   = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*|\?)\}`)
+	forLoop    = regexp.MustCompile(`^for ([A-Za-z_][A-Za-z0-9_]*) in(?: (.*))?$`)
+)
+
+type stmt struct {
+	kind int
+	n    int
+
+	// command, condition or loop words
+	line string
+	// loop variable
+	name string
+	// negated condition
+	not bool
+
+	body   []*stmt
+	orelse []*stmt
+}
+
+// expand replaces ${name} occurrences with the corresponding variable value,
+// undefined variables are replaced with an empty string.
+func (c *Interface) expand(line string) string {
+	return variable.ReplaceAllStringFunc(line, func(s string) string {
+		return c.Vars[s[2:len(s)-1]]
+	})
+}
+
+func (c *Interface) setVar(name string, value string) {
+	if c.Vars == nil {
+		c.Vars = make(map[string]string)
+	}
+
+	c.Vars[name] = value
+}
+
+// assign handles `name=value` variable assignments.
+func (c *Interface) assign(line string) bool {
+	m := assignment.FindStringSubmatch(line)
+
+	if len(m) == 0 {
+		return false
+	}
+
+	c.setVar(m[1], m[2])
+
+	return true
+}
+
+// parseScript parses script lines into statements, blocks are terminated by
+// `end`.
+func parseScript(script string) (stmts []*stmt, err error) {
+	var stack []*stmt
+	var n int
+
+	// current returns the block being appended to
+	current := func() *[]*stmt {
+		if len(stack) == 0 {
+			return &stmts
+		}
+
+		s := stack[len(stack)-1]
+
+		if s.orelse != nil {
+			return &s.orelse
+		}
+
+		return &s.body
+	}
+
+	for line := range strings.Lines(script) {
+		n += 1
+		line = strings.TrimSpace(line)
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		s := &stmt{n: n}
+
+		switch {
+		case line == "if" || strings.HasPrefix(line, "if "):
+			s.kind = stmtIf
+			s.line = strings.TrimSpace(strings.TrimPrefix(line, "if"))
+
+			if cond, ok := strings.CutPrefix(s.line, "! "); ok {
+				s.line = strings.TrimSpace(cond)
+				s.not = true
+			}
+
+			if len(s.line) == 0 {
+				return nil, fmt.Errorf("line %d: missing condition", n)
+			}
+		case strings.HasPrefix(line, "for "):
+			m := forLoop.FindStringSubmatch(line)
+
+			if len(m) == 0 {
+				return nil, fmt.Errorf("line %d: invalid loop, expected `for <name> in <words>`", n)
+			}
+
+			s.kind = stmtFor
+			s.name = m[1]
+			s.line = m[2]
+		case line == "else":
+			if len(stack) == 0 || stack[len(stack)-1].kind != stmtIf || stack[len(stack)-1].orelse != nil {
+				return nil, fmt.Errorf("line %d: unexpected else", n)
+			}
+
+			stack[len(stack)-1].orelse = []*stmt{}
+			continue
+		case line == "end":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: unexpected end", n)
+			}
+
+			stack = stack[:len(stack)-1]
+			continue
+		default:
+			s.line = line
+		}
+
+		*current() = append(*current(), s)
+
+		if s.kind != stmtCmd {
+			stack = append(stack, s)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("line %d: missing end", stack[len(stack)-1].n)
+	}
+
+	return
+}
+
+func (c *Interface) run(stmts []*stmt) (err error) {
+	for _, s := range stmts {
+		if err = c.ctx.Err(); err != nil {
+			return
+		}
+
+		switch s.kind {
+		case stmtCmd:
+			if err = c.handleLine(s.line); err == io.EOF {
+				return
+			}
+
+			if err != nil && !isReported(err) {
+				fmt.Fprintf(c.Output, "command error (line %d), %v\n", s.n, err)
+			}
+		case stmtIf:
+			if err = c.handleLine(s.line); err == io.EOF {
+				return
+			}
+
+			if (err == nil) != s.not {
+				err = c.run(s.body)
+			} else {
+				err = c.run(s.orelse)
+			}
+
+			if err != nil {
+				return
+			}
+		case stmtFor:
+			for _, w := range strings.Fields(c.expand(s.line)) {
+				c.setVar(s.name, w)
+
+				if err = c.run(s.body); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Run executes a script, each line is handled as an individual command with
+// the following additions:
+//
+//	name=value          set variable, expanded as ${name} in later lines
+//	if (!)? <command>   execute block on command success (or failure)
+//	else                alternate block
+//	for <name> in <w..> execute block for each word
+//	end                 close block
+//
+// The ${?} variable holds the last command status (0 on success). Command
+// errors are reported without terminating the script, unless the command
+// requests the interface termination (e.g. `exit`), in which case io.EOF is
+// returned, or the script is interrupted with Ctrl-C.
+func (c *Interface) Run(script string) (err error) {
+	var stmts []*stmt
+
+	if c.depth >= maxScriptDepth {
+		return errors.New("script nesting too deep")
+	}
+
+	if stmts, err = parseScript(script); err != nil {
+		return
+	}
+
+	_, done := c.interruptible()
+	defer done()
+
+	c.depth += 1
+	defer func() { c.depth -= 1 }()
+
+	return c.run(stmts)
+}
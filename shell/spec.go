@@ -0,0 +1,227 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+// ArgKind represents a command argument type.
+type ArgKind int
+
+const (
+	// String represents a single word argument.
+	String ArgKind = iota
+	// Int represents a decimal integer argument.
+	Int
+	// Uint represents a non-negative decimal integer argument (e.g. an
+	// index or size).
+	Uint
+	// Hex represents an hexadecimal integer argument, an optional `0x`
+	// prefix is stripped before invoking the command handler.
+	Hex
+	// Enum represents an argument restricted to [Arg.Values].
+	Enum
+	// Flag represents an optional keyword, its value is [Arg.Name] when
+	// present or empty otherwise.
+	Flag
+	// Text represents an argument spanning the rest of the line, it must be
+	// the last one.
+	Text
+)
+
+// Arg represents a declarative command argument specification.
+type Arg struct {
+	// Name is the argument name.
+	Name string
+	// Kind is the argument type.
+	Kind ArgKind
+	// Values defines the allowed values for [Enum] arguments.
+	Values []string
+	// Optional defines whether the argument can be omitted.
+	Optional bool
+	// Default defines the value passed to the command handler when an
+	// optional argument is omitted.
+	Default string
+	// Help defines the `help <command>` argument description.
+	Help string
+}
+
+// empty line alias (e.g. `linux,l,\r`)
+const enterAlias = `\r`
+
+func (a *Arg) pattern() string {
+	switch a.Kind {
+	case Int:
+		return `(-?\d+)`
+	case Uint:
+		return `(\d+)`
+	case Hex:
+		return `((?:0x)?[[:xdigit:]]+)`
+	case Enum:
+		var values []string
+
+		for _, v := range a.Values {
+			values = append(values, regexp.QuoteMeta(v))
+		}
+
+		return `(` + strings.Join(values, "|") + `)`
+	case Flag:
+		return `(` + regexp.QuoteMeta(a.Name) + `)`
+	case Text:
+		return `(.+)`
+	default:
+		return `(\S+)`
+	}
+}
+
+func (a *Arg) syntax() (s string) {
+	switch a.Kind {
+	case Enum:
+		s = strings.Join(a.Values, "|")
+
+		if !a.Optional {
+			return "(" + s + ")"
+		}
+	case Flag:
+		return "(" + a.Name + ")?"
+	default:
+		s = "<" + a.Name + ">"
+
+		if !a.Optional {
+			return
+		}
+	}
+
+	return "(" + s + ")?"
+}
+
+// aliases returns the command name aliases.
+func (cmd *Cmd) aliases() (names []string) {
+	for name := range strings.SplitSeq(cmd.Name, ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+
+	return
+}
+
+// literals returns the largest number of literal tokens among the command
+// name aliases (e.g. 2 for `acpi dump`).
+func (cmd *Cmd) literals() (n int) {
+	for _, name := range cmd.aliases() {
+		n = max(n, len(strings.Fields(name)))
+	}
+
+	return
+}
+
+// compile derives the command Pattern, Args and Syntax from its Spec.
+func (cmd *Cmd) compile() (err error) {
+	var alt []string
+	var syntax []string
+
+	for _, name := range cmd.aliases() {
+		if name == enterAlias {
+			name = ""
+		}
+
+		alt = append(alt, regexp.QuoteMeta(name))
+	}
+
+	expr := `^(?:` + strings.Join(alt, "|") + `)`
+
+	for i, a := range cmd.Spec {
+		switch {
+		case len(a.Name) == 0:
+			return fmt.Errorf("argument %d lacks a name", i)
+		case a.Kind == Enum && len(a.Values) == 0:
+			return fmt.Errorf("enum argument %s lacks values", a.Name)
+		case a.Kind == Text && i != len(cmd.Spec)-1:
+			return fmt.Errorf("text argument %s is not the last one", a.Name)
+		}
+
+		if a.Optional || a.Kind == Flag {
+			expr += `(?: ` + a.pattern() + `)?`
+		} else {
+			expr += ` ` + a.pattern()
+		}
+
+		syntax = append(syntax, a.syntax())
+	}
+
+	if cmd.Pattern, err = regexp.Compile(expr + `$`); err != nil {
+		return
+	}
+
+	cmd.Args = len(cmd.Spec)
+
+	if len(cmd.Syntax) == 0 {
+		cmd.Syntax = strings.Join(syntax, " ")
+	}
+
+	return
+}
+
+// values applies the Spec defaults and normalization to the matched
+// arguments.
+func (cmd *Cmd) values(arg []string) []string {
+	for i, a := range cmd.Spec {
+		switch {
+		case len(arg[i]) == 0:
+			arg[i] = a.Default
+		case a.Kind == Hex:
+			arg[i] = strings.TrimPrefix(arg[i], "0x")
+		}
+	}
+
+	return arg
+}
+
+// Usage returns the command help page.
+func (cmd *Cmd) Usage() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s %s\n", strings.Join(cmd.aliases(), ","), cmd.Syntax)
+
+	if len(cmd.Help) > 0 {
+		fmt.Fprintf(&buf, "\n  %s\n", cmd.Help)
+	}
+
+	if len(cmd.Spec) == 0 {
+		return buf.String()
+	}
+
+	fmt.Fprintln(&buf)
+	t := tabwriter.NewWriter(&buf, 4, 8, 2, ' ', 0)
+
+	for _, a := range cmd.Spec {
+		var attr []string
+
+		if a.Kind == Enum {
+			attr = append(attr, "values: "+strings.Join(a.Values, ", "))
+		}
+
+		if len(a.Default) > 0 {
+			attr = append(attr, "default: "+a.Default)
+		}
+
+		desc := a.Help
+
+		if len(attr) > 0 {
+			desc = fmt.Sprintf("%s (%s)", desc, strings.Join(attr, ", "))
+		}
+
+		fmt.Fprintf(t, "  %s\t%s\n", a.Name, strings.TrimSpace(desc))
+	}
+
+	t.Flush()
+
+	return buf.String()
+}
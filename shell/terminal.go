@@ -8,7 +8,8 @@
 package shell
 
 import (
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -37,6 +38,14 @@ type Interface struct {
 
 	// Log represents the interface log file
 	Log *os.File
+	// LogSink represents the interface session log output, it receives
+	// standard logger output while the interface is running (see
+	// [Logs]).
+	LogSink io.Writer
+
+	// Commands represents the interface command set, when nil
+	// [DefaultCommands] is used.
+	Commands *Registry
 
 	// ReadWriter represents the terminal connection
 	ReadWriter io.ReadWriter
@@ -51,8 +60,37 @@ type Interface struct {
 	// Pagination enables console pagination to avoid frame buffer
 	// scrolling.
 	Pagination bool
+	// JSON enables JSON output mode for all commands, otherwise enabled
+	// with the `json` prefix or `--json` suffix.
+	JSON bool
+
+	// Authenticate, when set, verifies the password required before
+	// accepting interactive commands.
+	Authenticate func(password string) bool
+	// Lockdown disables command line editing, history and completion.
+	Lockdown bool
+
+	// Root represents the file system for output redirection, command
+	// history and path completion, when nil these are not available.
+	Root *uefi.FS
+	// HistoryPath represents the command history file on Root, when empty
+	// history is not persisted.
+	HistoryPath string
+
+	// Vars represents the interface variables, expanded as ${name}.
+	Vars map[string]string
+	// Startup represents a script executed, after any authentication,
+	// before interactive operation (see [Interface.Run]).
+	Startup string
 
 	t   *term.Terminal
+
+	// background input reader
+	in *input
+	// running command context
+	ctx context.Context
+	// script nesting level
+	depth int
 }
 
 func (c *Interface) paginate(prompt bool) (err error) {
@@ -82,31 +120,64 @@ func (c *Interface) paginate(prompt bool) (err error) {
 }
 
 func (c *Interface) handleLine(line string) (err error) {
-	var match *Cmd
+	var cmd *Cmd
 	var arg []string
 	var res string
 
-	for _, cmd := range cmds {
-		if cmd.Pattern == nil {
-			if cmd.Name == line {
-				match = cmd
-				break
-			}
-		} else if m := cmd.Pattern.FindStringSubmatch(line); len(m) > 0 && (len(m)-1 == cmd.Args) {
-			match = cmd
-			arg = m[1:]
-			break
+	line = alias(c.expand(line))
+
+	if c.assign(line) {
+		return
+	}
+
+	defer func() {
+		status := "0"
+
+		if err != nil {
+			status = "1"
 		}
+
+		c.setVar("?", status)
+	}()
+
+	line, stages, name, flag := pipeline(line)
+
+	if line, json := c.jsonMode(line); json {
+		return c.handleJSON(line, stages, name, flag)
 	}
 
-	if match == nil {
-		return errors.New("unknown command, type `help`")
+	if cmd, arg, err = c.commands().match(line); err != nil {
+		return
+	}
+
+	ctx, done := c.interruptible()
+	defer done()
+
+	switch {
+	case cmd.Stream != nil && len(stages) == 0 && len(name) == 0:
+		return cmd.Stream(ctx, c, c.Output, arg)
+	case cmd.Stream != nil:
+		var buf bytes.Buffer
+		err = cmd.Stream(ctx, c, &buf, arg)
+		res = buf.String()
+	default:
+		res, err = cmd.Fn(c, arg)
 	}
 
-	if res, err = match.Fn(c, arg); err != nil {
+	if err != nil {
 		return
 	}
 
+	for _, stage := range stages {
+		if res, err = filter(res, stage); err != nil {
+			return
+		}
+	}
+
+	if len(name) > 0 {
+		return c.redirect(name, flag, res)
+	}
+
 	if len(res) == 0 {
 		return
 	}
@@ -157,7 +228,10 @@ func (c *Interface) readLine() error {
 			return err
 		}
 
-		fmt.Fprintf(c.Output, "command error, %v\n", err)
+		if !isReported(err) {
+			fmt.Fprintf(c.Output, "command error, %v\n", err)
+		}
+
 		return nil
 	}
 
@@ -166,12 +240,25 @@ func (c *Interface) readLine() error {
 
 // Exec executes an individual command.
 func (c *Interface) Exec(cmd []byte) {
-	if err := c.handleLine(string(cmd)); err != nil {
+	if err := c.handleLine(string(cmd)); err != nil && !isReported(err) {
 		fmt.Fprintf(c.Output, "command error (%s), %v\n", cmd, err)
 	}
 }
 
+// commands returns the interface command set.
+func (c *Interface) commands() *Registry {
+	if c.Commands == nil {
+		return DefaultCommands
+	}
+
+	return c.Commands
+}
+
 func (c *Interface) handle() {
+	if c.LogSink != nil {
+		defer Logs.Attach(c.LogSink)()
+	}
+
 	if len(c.Prompt) == 0 {
 		c.Prompt = DefaultPrompt
 	}
@@ -182,7 +269,38 @@ func (c *Interface) handle() {
 		c.Output = c.ReadWriter
 	}
 
+	if c.Lockdown {
+		// commands are not recalled, not even in memory
+		c.t.History = noHistory{}
+	} else {
+		h := &history{}
+
+		if len(c.HistoryPath) > 0 {
+			h.root = c.Root
+			h.path = c.HistoryPath
+			h.load()
+		}
+
+		c.t.History = h
+		c.t.AutoCompleteCallback = c.complete
+	}
+
 	fmt.Fprintf(c.t, "\n%s\n\n", c.Banner)
+
+	if c.Authenticate != nil {
+		if err := c.authenticate(); err != nil {
+			return
+		}
+	}
+
+	if len(c.Startup) > 0 {
+		if err := c.Run(c.Startup); err == io.EOF {
+			return
+		} else if err != nil {
+			fmt.Fprintf(c.Output, "startup script error, %v\n", err)
+		}
+	}
+
 	Help(c, nil)
 
 	for {
@@ -192,21 +310,62 @@ func (c *Interface) handle() {
 	}
 }
 
+// consoleSize returns the UEFI console text mode size, or the size reported
+// by other consoles (e.g. framebuffer).
+func consoleSize(rw io.ReadWriter) (cols int, rows int) {
+	switch console := rw.(type) {
+	case *uefi.Console:
+		mode, err := console.GetMode()
+
+		if err != nil {
+			return
+		}
+
+		if c, r, err := console.QueryMode(uint64(mode.Mode)); err == nil {
+			return int(c), int(r)
+		}
+	case interface{ Size() (int, int) }:
+		return console.Size()
+	}
+
+	return
+}
+
 // Start handles registered commands over the interface Terminal or ReadWriter,
 // the argument specifies whether ReadWriter is VT100 compatible.
 func (c *Interface) Start(vt100 bool) {
-	Add(Cmd{
+	c.commands().Add(Cmd{
 		Name: "help",
+		Spec: []Arg{
+			{Name: "command", Kind: Text, Optional: true, Help: "show command help page"},
+		},
 		Help: "this help",
 		Fn:   Help,
 	})
 
+	c.commands().Add(Cmd{
+		Name: "json",
+		Spec: []Arg{
+			{Name: "mode", Kind: Enum, Values: []string{"on", "off"}},
+		},
+		Help: "JSON output mode, or `json <command>`",
+		Fn:   jsonCmd,
+	})
+
 	switch {
 	case c.Terminal != nil:
 		c.t = c.Terminal
 		c.handle()
 	case c.ReadWriter != nil:
-		c.t = term.NewTerminal(c.ReadWriter, "")
+		c.in = newInput(c.ReadWriter, c.Lockdown)
+		c.t = term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{c.in, c.ReadWriter}, "")
+
+		if cols, rows := consoleSize(c.ReadWriter); cols > 0 {
+			c.t.SetSize(cols, rows)
+		}
 
 		if vt100 {
 			c.Terminal = c.t
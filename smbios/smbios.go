@@ -0,0 +1,232 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+// Package smbios implements parsing of System Management BIOS (SMBIOS)
+// structures following the specifications at:
+//
+//	https://www.dmtf.org/standards/smbios
+package smbios
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	anchor2 = "_SM_"
+	anchor3 = "_SM3_"
+
+	// SMBIOS 2.1 (32-bit) entry point size
+	entryPoint2Size = 0x1f
+	// SMBIOS 3.0 (64-bit) entry point size
+	entryPoint3Size = 0x18
+
+	headerSize   = 4
+	maxTableSize = 1 << 20
+)
+
+// SMBIOS Structure Types
+const (
+	TypeBIOS         = 0
+	TypeSystem       = 1
+	TypeBaseboard    = 2
+	TypeChassis      = 3
+	TypeProcessor    = 4
+	TypeOEMStrings   = 11
+	TypeMemoryDevice = 17
+	TypeEndOfTable   = 127
+)
+
+// Memory represents a function to copy physical memory.
+type Memory func(addr uint, size int) []byte
+
+// EntryPoint represents an SMBIOS 2.1 (32-bit) or 3.0 (64-bit) entry point
+// structure.
+type EntryPoint struct {
+	// Major is the SMBIOS major version.
+	Major uint8
+	// Minor is the SMBIOS minor version.
+	Minor uint8
+	// Revision is the SMBIOS docrev (3.0 only).
+	Revision uint8
+
+	// Address is the structure table physical address.
+	Address uint64
+	// Size is the structure table (maximum) size.
+	Size uint32
+	// Count is the number of structures (2.1 only).
+	Count uint16
+}
+
+// Version returns the SMBIOS version string.
+func (ep *EntryPoint) Version() string {
+	return fmt.Sprintf("%d.%d.%d", ep.Major, ep.Minor, ep.Revision)
+}
+
+// Structure represents a generic SMBIOS structure.
+type Structure struct {
+	// Type is the structure type.
+	Type uint8
+	// Handle is the structure handle.
+	Handle uint16
+	// Formatted is the structure formatted area, including its header so
+	// that specification offsets can be used directly.
+	Formatted []byte
+	// Strings is the structure string set.
+	Strings []string
+}
+
+func checksum(buf []byte) bool {
+	var sum uint8
+
+	for _, b := range buf {
+		sum += b
+	}
+
+	return sum == 0
+}
+
+// ParseEntryPoint reads and validates the SMBIOS 3.0 or 2.1 entry point at the
+// argument address.
+func ParseEntryPoint(mem Memory, addr uint) (ep *EntryPoint, err error) {
+	buf := mem(addr, entryPoint2Size)
+
+	switch {
+	case bytes.HasPrefix(buf, []byte(anchor3)):
+		if n := int(buf[6]); n < entryPoint3Size || n > len(buf) || !checksum(buf[:n]) {
+			return nil, errors.New("invalid SMBIOS 3.0 entry point checksum")
+		}
+
+		ep = &EntryPoint{
+			Major:    buf[7],
+			Minor:    buf[8],
+			Revision: buf[9],
+			Size:     binary.LittleEndian.Uint32(buf[12:]),
+			Address:  binary.LittleEndian.Uint64(buf[16:]),
+		}
+	case bytes.HasPrefix(buf, []byte(anchor2)):
+		if buf[5] < entryPoint2Size || !checksum(buf[:entryPoint2Size]) {
+			return nil, errors.New("invalid SMBIOS 2.1 entry point checksum")
+		}
+
+		ep = &EntryPoint{
+			Major:   buf[6],
+			Minor:   buf[7],
+			Size:    uint32(binary.LittleEndian.Uint16(buf[22:])),
+			Address: uint64(binary.LittleEndian.Uint32(buf[24:])),
+			Count:   binary.LittleEndian.Uint16(buf[28:]),
+		}
+	default:
+		return nil, errors.New("invalid SMBIOS entry point anchor")
+	}
+
+	if ep.Address == 0 || ep.Size == 0 || ep.Size > maxTableSize {
+		return nil, errors.New("invalid SMBIOS structure table")
+	}
+
+	return
+}
+
+// Structures reads and parses the structure table referenced by the argument
+// entry point.
+func Structures(mem Memory, ep *EntryPoint) (s []*Structure, err error) {
+	buf := mem(uint(ep.Address), int(ep.Size))
+
+	for off := 0; off+headerSize <= len(buf); {
+		if ep.Count > 0 && len(s) == int(ep.Count) {
+			break
+		}
+
+		n := int(buf[off+1])
+
+		if n < headerSize || off+n > len(buf) {
+			return nil, fmt.Errorf("invalid structure length at %#x", off)
+		}
+
+		st := &Structure{
+			Type:      buf[off],
+			Handle:    binary.LittleEndian.Uint16(buf[off+2:]),
+			Formatted: buf[off : off+n],
+		}
+
+		off += n
+
+		// string set, terminated by a double null
+		end := bytes.Index(buf[off:], []byte{0x00, 0x00})
+
+		if end < 0 {
+			return nil, fmt.Errorf("invalid structure strings at %#x", off)
+		}
+
+		if end > 0 {
+			for _, str := range bytes.Split(buf[off:off+end], []byte{0x00}) {
+				st.Strings = append(st.Strings, string(str))
+			}
+		}
+
+		off += end + 2
+
+		if st.Type == TypeEndOfTable {
+			break
+		}
+
+		s = append(s, st)
+	}
+
+	return
+}
+
+// StringAt returns the string referenced at the argument formatted area offset.
+func (s *Structure) StringAt(off int) string {
+	i := int(s.Byte(off))
+
+	if i == 0 || i > len(s.Strings) {
+		return ""
+	}
+
+	return s.Strings[i-1]
+}
+
+// Byte returns the byte at the argument formatted area offset, or zero when
+// not present.
+func (s *Structure) Byte(off int) uint8 {
+	if off+1 > len(s.Formatted) {
+		return 0
+	}
+
+	return s.Formatted[off]
+}
+
+// Word returns the word at the argument formatted area offset, or zero when
+// not present.
+func (s *Structure) Word(off int) uint16 {
+	if off+2 > len(s.Formatted) {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint16(s.Formatted[off:])
+}
+
+// DWord returns the double word at the argument formatted area offset, or
+// zero when not present.
+func (s *Structure) DWord(off int) uint32 {
+	if off+4 > len(s.Formatted) {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint32(s.Formatted[off:])
+}
+
+// QWord returns the quad word at the argument formatted area offset, or zero
+// when not present.
+func (s *Structure) QWord(off int) uint64 {
+	if off+8 > len(s.Formatted) {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint64(s.Formatted[off:])
+}
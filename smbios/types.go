@@ -0,0 +1,380 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"fmt"
+)
+
+var chassisTypes = []string{
+	"",
+	"Other",
+	"Unknown",
+	"Desktop",
+	"Low Profile Desktop",
+	"Pizza Box",
+	"Mini Tower",
+	"Tower",
+	"Portable",
+	"Laptop",
+	"Notebook",
+	"Hand Held",
+	"Docking Station",
+	"All in One",
+	"Sub Notebook",
+	"Space-saving",
+	"Lunch Box",
+	"Main Server Chassis",
+	"Expansion Chassis",
+	"SubChassis",
+	"Bus Expansion Chassis",
+	"Peripheral Chassis",
+	"RAID Chassis",
+	"Rack Mount Chassis",
+	"Sealed-case PC",
+	"Multi-system chassis",
+	"Compact PCI",
+	"Advanced TCA",
+	"Blade",
+	"Blade Enclosure",
+	"Tablet",
+	"Convertible",
+	"Detachable",
+	"IoT Gateway",
+	"Embedded PC",
+	"Mini PC",
+	"Stick PC",
+}
+
+var memoryTypes = map[uint8]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "DRAM",
+	0x07: "RAM",
+	0x09: "NVRAM",
+	0x0f: "SDRAM",
+	0x12: "DDR",
+	0x13: "DDR2",
+	0x18: "DDR3",
+	0x1a: "DDR4",
+	0x1b: "LPDDR",
+	0x1c: "LPDDR2",
+	0x1d: "LPDDR3",
+	0x1e: "LPDDR4",
+	0x1f: "Logical non-volatile device",
+	0x20: "HBM",
+	0x21: "HBM2",
+	0x22: "DDR5",
+	0x23: "LPDDR5",
+	0x24: "HBM3",
+}
+
+// BIOS represents a BIOS Information (Type 0) structure.
+type BIOS struct {
+	Vendor          string
+	Version         string
+	ReleaseDate     string
+	StartingSegment uint16
+	// ROMSize is the BIOS ROM size in bytes.
+	ROMSize         uint64
+	Characteristics uint64
+	MajorRelease    uint8
+	MinorRelease    uint8
+}
+
+// System represents a System Information (Type 1) structure.
+type System struct {
+	Manufacturer string
+	ProductName  string
+	Version      string
+	SerialNumber string
+	UUID         UUID
+	WakeUpType   uint8
+	SKUNumber    string
+	Family       string
+}
+
+// Baseboard represents a Baseboard Information (Type 2) structure.
+type Baseboard struct {
+	Manufacturer      string
+	Product           string
+	Version           string
+	SerialNumber      string
+	AssetTag          string
+	FeatureFlags      uint8
+	LocationInChassis string
+	BoardType         uint8
+}
+
+// Chassis represents a System Enclosure or Chassis (Type 3) structure.
+type Chassis struct {
+	Manufacturer string
+	Type         uint8
+	Version      string
+	SerialNumber string
+	AssetTag     string
+	SKUNumber    string
+}
+
+// TypeName returns the chassis type name.
+func (c *Chassis) TypeName() string {
+	if t := int(c.Type & 0x7f); t < len(chassisTypes) && t > 0 {
+		return chassisTypes[t]
+	}
+
+	return fmt.Sprintf("%#x", c.Type)
+}
+
+// Processor represents a Processor Information (Type 4) structure.
+type Processor struct {
+	SocketDesignation string
+	ProcessorType     uint8
+	Family            uint16
+	Manufacturer      string
+	ID                uint64
+	Version           string
+	// MaxSpeed is the maximum processor speed in MHz.
+	MaxSpeed uint16
+	// CurrentSpeed is the processor speed at boot in MHz.
+	CurrentSpeed uint16
+	Status       uint8
+	SerialNumber string
+	AssetTag     string
+	PartNumber   string
+	CoreCount    uint16
+	CoreEnabled  uint16
+	ThreadCount  uint16
+}
+
+// Populated returns whether the processor socket is populated.
+func (p *Processor) Populated() bool {
+	return p.Status&(1<<6) != 0
+}
+
+// MemoryDevice represents a Memory Device (Type 17) structure.
+type MemoryDevice struct {
+	DeviceLocator string
+	BankLocator   string
+	// Size is the memory device size in bytes, zero when not installed.
+	Size         uint64
+	FormFactor   uint8
+	Type         uint8
+	Speed        uint16
+	Manufacturer string
+	SerialNumber string
+	AssetTag     string
+	PartNumber   string
+}
+
+// TypeName returns the memory device type name.
+func (m *MemoryDevice) TypeName() string {
+	if name, ok := memoryTypes[m.Type]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("%#x", m.Type)
+}
+
+// UUID represents an SMBIOS system UUID.
+type UUID [16]byte
+
+// String returns the UUID string representation, the first three fields are
+// encoded in little-endian format as mandated since SMBIOS 2.6.
+func (u UUID) String() string {
+	return fmt.Sprintf("%02x%02x%02x%02x-%02x%02x-%02x%02x-%x-%x",
+		u[3], u[2], u[1], u[0], u[5], u[4], u[7], u[6], u[8:10], u[10:])
+}
+
+func parseBIOS(s *Structure) *BIOS {
+	b := &BIOS{
+		Vendor:          s.StringAt(0x04),
+		Version:         s.StringAt(0x05),
+		StartingSegment: s.Word(0x06),
+		ReleaseDate:     s.StringAt(0x08),
+		ROMSize:         (uint64(s.Byte(0x09)) + 1) << 16,
+		Characteristics: s.QWord(0x0a),
+		MajorRelease:    s.Byte(0x14),
+		MinorRelease:    s.Byte(0x15),
+	}
+
+	if b.ROMSize == 256<<16 {
+		// Extended BIOS ROM Size
+		ext := uint64(s.Word(0x18))
+		unit := uint64(1 << 20)
+
+		if ext>>14 == 1 {
+			unit = 1 << 30
+		}
+
+		b.ROMSize = (ext & 0x3fff) * unit
+	}
+
+	return b
+}
+
+func parseSystem(s *Structure) *System {
+	sys := &System{
+		Manufacturer: s.StringAt(0x04),
+		ProductName:  s.StringAt(0x05),
+		Version:      s.StringAt(0x06),
+		SerialNumber: s.StringAt(0x07),
+		WakeUpType:   s.Byte(0x18),
+		SKUNumber:    s.StringAt(0x19),
+		Family:       s.StringAt(0x1a),
+	}
+
+	if len(s.Formatted) >= 0x18 {
+		copy(sys.UUID[:], s.Formatted[0x08:0x18])
+	}
+
+	return sys
+}
+
+func parseBaseboard(s *Structure) *Baseboard {
+	return &Baseboard{
+		Manufacturer:      s.StringAt(0x04),
+		Product:           s.StringAt(0x05),
+		Version:           s.StringAt(0x06),
+		SerialNumber:      s.StringAt(0x07),
+		AssetTag:          s.StringAt(0x08),
+		FeatureFlags:      s.Byte(0x09),
+		LocationInChassis: s.StringAt(0x0a),
+		BoardType:         s.Byte(0x0d),
+	}
+}
+
+func parseChassis(s *Structure) *Chassis {
+	c := &Chassis{
+		Manufacturer: s.StringAt(0x04),
+		Type:         s.Byte(0x05),
+		Version:      s.StringAt(0x06),
+		SerialNumber: s.StringAt(0x07),
+		AssetTag:     s.StringAt(0x08),
+	}
+
+	// SKU Number follows the contained elements
+	n := int(s.Byte(0x13))
+	m := int(s.Byte(0x14))
+	c.SKUNumber = s.StringAt(0x15 + n*m)
+
+	return c
+}
+
+func parseProcessor(s *Structure) *Processor {
+	p := &Processor{
+		SocketDesignation: s.StringAt(0x04),
+		ProcessorType:     s.Byte(0x05),
+		Family:            uint16(s.Byte(0x06)),
+		Manufacturer:      s.StringAt(0x07),
+		ID:                s.QWord(0x08),
+		Version:           s.StringAt(0x10),
+		MaxSpeed:          s.Word(0x14),
+		CurrentSpeed:      s.Word(0x16),
+		Status:            s.Byte(0x18),
+		SerialNumber:      s.StringAt(0x20),
+		AssetTag:          s.StringAt(0x21),
+		PartNumber:        s.StringAt(0x22),
+		CoreCount:         uint16(s.Byte(0x23)),
+		CoreEnabled:       uint16(s.Byte(0x24)),
+		ThreadCount:       uint16(s.Byte(0x25)),
+	}
+
+	if p.Family == 0xfe {
+		p.Family = s.Word(0x28)
+	}
+
+	if p.CoreCount == 0xff {
+		p.CoreCount = s.Word(0x2a)
+	}
+
+	if p.CoreEnabled == 0xff {
+		p.CoreEnabled = s.Word(0x2c)
+	}
+
+	if p.ThreadCount == 0xff {
+		p.ThreadCount = s.Word(0x2e)
+	}
+
+	return p
+}
+
+func parseMemoryDevice(s *Structure) *MemoryDevice {
+	m := &MemoryDevice{
+		FormFactor:    s.Byte(0x0e),
+		DeviceLocator: s.StringAt(0x10),
+		BankLocator:   s.StringAt(0x11),
+		Type:          s.Byte(0x12),
+		Speed:         s.Word(0x15),
+		Manufacturer:  s.StringAt(0x17),
+		SerialNumber:  s.StringAt(0x18),
+		AssetTag:      s.StringAt(0x19),
+		PartNumber:    s.StringAt(0x1a),
+	}
+
+	switch size := s.Word(0x0c); {
+	case size == 0xffff:
+		// unknown
+	case size == 0x7fff:
+		m.Size = uint64(s.DWord(0x1c)&0x7fffffff) << 20
+	case size&0x8000 != 0:
+		m.Size = uint64(size&0x7fff) << 10
+	default:
+		m.Size = uint64(size) << 20
+	}
+
+	return m
+}
+
+// SMBIOS represents the parsed SMBIOS structure table.
+type SMBIOS struct {
+	// EntryPoint is the SMBIOS entry point.
+	EntryPoint *EntryPoint
+	// Structures are all SMBIOS structures, including unparsed types.
+	Structures []*Structure
+
+	BIOS          *BIOS
+	System        *System
+	Baseboards    []*Baseboard
+	Chassis       []*Chassis
+	Processors    []*Processor
+	MemoryDevices []*MemoryDevice
+	OEMStrings    []string
+}
+
+// Parse reads and parses the SMBIOS structure table referenced by the entry
+// point at the argument address.
+func Parse(mem Memory, addr uint) (t *SMBIOS, err error) {
+	t = &SMBIOS{}
+
+	if t.EntryPoint, err = ParseEntryPoint(mem, addr); err != nil {
+		return nil, err
+	}
+
+	if t.Structures, err = Structures(mem, t.EntryPoint); err != nil {
+		return nil, err
+	}
+
+	for _, s := range t.Structures {
+		switch s.Type {
+		case TypeBIOS:
+			t.BIOS = parseBIOS(s)
+		case TypeSystem:
+			t.System = parseSystem(s)
+		case TypeBaseboard:
+			t.Baseboards = append(t.Baseboards, parseBaseboard(s))
+		case TypeChassis:
+			t.Chassis = append(t.Chassis, parseChassis(s))
+		case TypeProcessor:
+			t.Processors = append(t.Processors, parseProcessor(s))
+		case TypeMemoryDevice:
+			t.MemoryDevices = append(t.MemoryDevices, parseMemoryDevice(s))
+		case TypeOEMStrings:
+			t.OEMStrings = append(t.OEMStrings, s.Strings...)
+		}
+	}
+
+	return
+}
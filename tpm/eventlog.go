@@ -0,0 +1,515 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package tpm
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// TCG PC Client Platform Firmware Profile Specification
+// Version 1.05 - 10.4.1 Event Types
+const (
+	EV_PREBOOT_CERT                  = 0x00000000
+	EV_POST_CODE                     = 0x00000001
+	EV_NO_ACTION                     = 0x00000003
+	EV_SEPARATOR                     = 0x00000004
+	EV_ACTION                        = 0x00000005
+	EV_EVENT_TAG                     = 0x00000006
+	EV_S_CRTM_CONTENTS               = 0x00000007
+	EV_S_CRTM_VERSION                = 0x00000008
+	EV_CPU_MICROCODE                 = 0x00000009
+	EV_PLATFORM_CONFIG_FLAGS         = 0x0000000a
+	EV_TABLE_OF_DEVICES              = 0x0000000b
+	EV_COMPACT_HASH                  = 0x0000000c
+	EV_IPL                           = 0x0000000d
+	EV_IPL_PARTITION_DATA            = 0x0000000e
+	EV_NONHOST_CODE                  = 0x0000000f
+	EV_NONHOST_CONFIG                = 0x00000010
+	EV_NONHOST_INFO                  = 0x00000011
+	EV_OMIT_BOOT_DEVICE_EVENTS       = 0x00000012
+	EV_EFI_VARIABLE_DRIVER_CONFIG    = 0x80000001
+	EV_EFI_VARIABLE_BOOT             = 0x80000002
+	EV_EFI_BOOT_SERVICES_APPLICATION = 0x80000003
+	EV_EFI_BOOT_SERVICES_DRIVER      = 0x80000004
+	EV_EFI_RUNTIME_SERVICES_DRIVER   = 0x80000005
+	EV_EFI_GPT_EVENT                 = 0x80000006
+	EV_EFI_ACTION                    = 0x80000007
+	EV_EFI_PLATFORM_FIRMWARE_BLOB    = 0x80000008
+	EV_EFI_HANDOFF_TABLES            = 0x80000009
+	EV_EFI_PLATFORM_FIRMWARE_BLOB2   = 0x8000000a
+	EV_EFI_HANDOFF_TABLES2           = 0x8000000b
+	EV_EFI_VARIABLE_BOOT2            = 0x8000000c
+	EV_EFI_HCRTM_EVENT               = 0x80000010
+	EV_EFI_VARIABLE_AUTHORITY        = 0x800000e0
+	EV_EFI_SPDM_FIRMWARE_BLOB        = 0x800000e1
+	EV_EFI_SPDM_FIRMWARE_CONFIG      = 0x800000e2
+)
+
+var eventTypes = map[uint32]string{
+	EV_PREBOOT_CERT:                  "EV_PREBOOT_CERT",
+	EV_POST_CODE:                     "EV_POST_CODE",
+	EV_NO_ACTION:                     "EV_NO_ACTION",
+	EV_SEPARATOR:                     "EV_SEPARATOR",
+	EV_ACTION:                        "EV_ACTION",
+	EV_EVENT_TAG:                     "EV_EVENT_TAG",
+	EV_S_CRTM_CONTENTS:               "EV_S_CRTM_CONTENTS",
+	EV_S_CRTM_VERSION:                "EV_S_CRTM_VERSION",
+	EV_CPU_MICROCODE:                 "EV_CPU_MICROCODE",
+	EV_PLATFORM_CONFIG_FLAGS:         "EV_PLATFORM_CONFIG_FLAGS",
+	EV_TABLE_OF_DEVICES:              "EV_TABLE_OF_DEVICES",
+	EV_COMPACT_HASH:                  "EV_COMPACT_HASH",
+	EV_IPL:                           "EV_IPL",
+	EV_IPL_PARTITION_DATA:            "EV_IPL_PARTITION_DATA",
+	EV_NONHOST_CODE:                  "EV_NONHOST_CODE",
+	EV_NONHOST_CONFIG:                "EV_NONHOST_CONFIG",
+	EV_NONHOST_INFO:                  "EV_NONHOST_INFO",
+	EV_OMIT_BOOT_DEVICE_EVENTS:       "EV_OMIT_BOOT_DEVICE_EVENTS",
+	EV_EFI_VARIABLE_DRIVER_CONFIG:    "EV_EFI_VARIABLE_DRIVER_CONFIG",
+	EV_EFI_VARIABLE_BOOT:             "EV_EFI_VARIABLE_BOOT",
+	EV_EFI_BOOT_SERVICES_APPLICATION: "EV_EFI_BOOT_SERVICES_APPLICATION",
+	EV_EFI_BOOT_SERVICES_DRIVER:      "EV_EFI_BOOT_SERVICES_DRIVER",
+	EV_EFI_RUNTIME_SERVICES_DRIVER:   "EV_EFI_RUNTIME_SERVICES_DRIVER",
+	EV_EFI_GPT_EVENT:                 "EV_EFI_GPT_EVENT",
+	EV_EFI_ACTION:                    "EV_EFI_ACTION",
+	EV_EFI_PLATFORM_FIRMWARE_BLOB:    "EV_EFI_PLATFORM_FIRMWARE_BLOB",
+	EV_EFI_HANDOFF_TABLES:            "EV_EFI_HANDOFF_TABLES",
+	EV_EFI_PLATFORM_FIRMWARE_BLOB2:   "EV_EFI_PLATFORM_FIRMWARE_BLOB2",
+	EV_EFI_HANDOFF_TABLES2:           "EV_EFI_HANDOFF_TABLES2",
+	EV_EFI_VARIABLE_BOOT2:            "EV_EFI_VARIABLE_BOOT2",
+	EV_EFI_HCRTM_EVENT:               "EV_EFI_HCRTM_EVENT",
+	EV_EFI_VARIABLE_AUTHORITY:        "EV_EFI_VARIABLE_AUTHORITY",
+	EV_EFI_SPDM_FIRMWARE_BLOB:        "EV_EFI_SPDM_FIRMWARE_BLOB",
+	EV_EFI_SPDM_FIRMWARE_CONFIG:      "EV_EFI_SPDM_FIRMWARE_CONFIG",
+}
+
+var algorithms = map[uint16]crypto.Hash{
+	TPM_ALG_SHA1:   crypto.SHA1,
+	TPM_ALG_SHA256: crypto.SHA256,
+	TPM_ALG_SHA384: crypto.SHA384,
+	TPM_ALG_SHA512: crypto.SHA512,
+}
+
+const (
+	specIDSignature   = "Spec ID Event03\x00"
+	localitySignature = "StartupLocality\x00"
+	sha1DigestSize    = 20
+	maxDigestSize     = 64
+)
+
+// AlgorithmSize represents a TCG_EfiSpecIdEventAlgorithmSize instance.
+type AlgorithmSize struct {
+	AlgorithmID uint16
+	DigestSize  uint16
+}
+
+// SpecIDEvent represents a TCG_EfiSpecIDEvent instance.
+type SpecIDEvent struct {
+	PlatformClass    uint32
+	SpecVersionMinor uint8
+	SpecVersionMajor uint8
+	SpecErrata       uint8
+	UintnSize        uint8
+	Algorithms       []AlgorithmSize
+	VendorInfo       []byte
+}
+
+// Digest represents a TPMT_HA instance.
+type Digest struct {
+	AlgorithmID uint16
+	Value       []byte
+}
+
+// Event represents a TCG_PCR_EVENT2 instance.
+type Event struct {
+	PCRIndex  uint32
+	EventType uint32
+	Digests   []Digest
+	Data      []byte
+}
+
+// EventLog represents a TCG PC Client crypto agile event log.
+type EventLog struct {
+	// SpecID is the log header Spec ID event.
+	SpecID *SpecIDEvent
+	// Locality is the startup locality, used for PCR 0 initialization.
+	Locality uint8
+	// Events are the log events, excluding the header.
+	Events []*Event
+}
+
+func (r *reader) le16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+
+	return 0
+}
+
+func (r *reader) le32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+
+	return 0
+}
+
+func (r *reader) le64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+
+	return 0
+}
+
+func parseSpecIDEvent(buf []byte) (s *SpecIDEvent, err error) {
+	if !bytes.HasPrefix(buf, []byte(specIDSignature)) {
+		return nil, errors.New("invalid Spec ID event signature")
+	}
+
+	r := &reader{buf: buf[len(specIDSignature):]}
+	s = &SpecIDEvent{}
+
+	s.PlatformClass = r.le32()
+	s.SpecVersionMinor = r.uint8()
+	s.SpecVersionMajor = r.uint8()
+	s.SpecErrata = r.uint8()
+	s.UintnSize = r.uint8()
+
+	n := r.le32()
+
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		alg := AlgorithmSize{
+			AlgorithmID: r.le16(),
+			DigestSize:  r.le16(),
+		}
+
+		if h, ok := algorithms[alg.AlgorithmID]; (ok && int(alg.DigestSize) != h.Size()) || alg.DigestSize > maxDigestSize {
+			return nil, fmt.Errorf("invalid digest size %d", alg.DigestSize)
+		}
+
+		s.Algorithms = append(s.Algorithms, alg)
+	}
+
+	s.VendorInfo = r.next(int(r.uint8()))
+
+	return s, r.err
+}
+
+func (l *EventLog) digestSize(alg uint16) (int, error) {
+	for _, a := range l.SpecID.Algorithms {
+		if a.AlgorithmID == alg {
+			return int(a.DigestSize), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown digest algorithm %#x", alg)
+}
+
+func (l *EventLog) parseEvent(r *reader) (e *Event, err error) {
+	e = &Event{
+		PCRIndex:  r.le32(),
+		EventType: r.le32(),
+	}
+
+	n := r.le32()
+
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		d := Digest{
+			AlgorithmID: r.le16(),
+		}
+
+		size, err := l.digestSize(d.AlgorithmID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		d.Value = r.next(size)
+		e.Digests = append(e.Digests, d)
+	}
+
+	e.Data = r.next(int(r.le32()))
+
+	return e, r.err
+}
+
+// MaxEventHeaderSize returns the maximum size of a TCG_PCR_EVENT2 header,
+// including its event size field, for the log digest algorithms.
+func (l *EventLog) MaxEventHeaderSize() (n int) {
+	n = 4 + 4 + 4 + 4
+
+	for _, a := range l.SpecID.Algorithms {
+		n += 2 + int(a.DigestSize)
+	}
+
+	return
+}
+
+// EventSize returns the size of the TCG_PCR_EVENT2 instance starting at the
+// argument buffer, which must be at least large enough to hold the event
+// header.
+func (l *EventLog) EventSize(buf []byte) (n int, err error) {
+	r := &reader{buf: buf}
+
+	r.le32()
+	r.le32()
+	count := r.le32()
+
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		size, err := l.digestSize(r.le16())
+
+		if err != nil {
+			return 0, err
+		}
+
+		r.next(size)
+	}
+
+	size := r.le32()
+
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	return len(buf) - len(r.buf) + int(size), nil
+}
+
+// ParseEventLog parses a TCG PC Client crypto agile event log, starting with
+// a TCG_PCClientPCREvent Spec ID event followed by TCG_PCR_EVENT2 events.
+func ParseEventLog(buf []byte) (l *EventLog, err error) {
+	r := &reader{buf: buf}
+
+	if r.le32() != 0 || r.le32() != EV_NO_ACTION {
+		return nil, errors.New("invalid log header")
+	}
+
+	// skip SHA1 digest
+	r.next(sha1DigestSize)
+	header := r.next(int(r.le32()))
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	l = &EventLog{}
+
+	if l.SpecID, err = parseSpecIDEvent(header); err != nil {
+		return nil, err
+	}
+
+	for len(r.buf) > 0 {
+		e, err := l.parseEvent(r)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid event %d, %v", len(l.Events), err)
+		}
+
+		if e.EventType == EV_NO_ACTION && bytes.HasPrefix(e.Data, []byte(localitySignature)) && len(e.Data) > len(localitySignature) {
+			l.Locality = e.Data[len(localitySignature)]
+		}
+
+		l.Events = append(l.Events, e)
+	}
+
+	return
+}
+
+// Replay computes the expected PCR values, for each supported digest
+// algorithm, from the log events. Only PCRs with at least one event are
+// returned.
+func (l *EventLog) Replay() (banks map[uint16]map[int][]byte) {
+	banks = make(map[uint16]map[int][]byte)
+
+	for _, a := range l.SpecID.Algorithms {
+		if _, ok := algorithms[a.AlgorithmID]; ok {
+			banks[a.AlgorithmID] = make(map[int][]byte)
+		}
+	}
+
+	for _, e := range l.Events {
+		if e.EventType == EV_NO_ACTION {
+			continue
+		}
+
+		for _, d := range e.Digests {
+			bank, ok := banks[d.AlgorithmID]
+
+			if !ok {
+				continue
+			}
+
+			h := algorithms[d.AlgorithmID].New()
+			pcr, ok := bank[int(e.PCRIndex)]
+
+			if !ok {
+				pcr = make([]byte, h.Size())
+
+				if e.PCRIndex == 0 {
+					pcr[len(pcr)-1] = l.Locality
+				}
+			}
+
+			h.Write(pcr)
+			h.Write(d.Value)
+
+			bank[int(e.PCRIndex)] = h.Sum(nil)
+		}
+	}
+
+	return
+}
+
+// Digest returns the event digest for the argument algorithm, if present.
+func (e *Event) Digest(alg uint16) []byte {
+	for _, d := range e.Digests {
+		if d.AlgorithmID == alg {
+			return d.Value
+		}
+	}
+
+	return nil
+}
+
+// Type returns the event type name.
+func (e *Event) Type() string {
+	if name, ok := eventTypes[e.EventType]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("%#08x", e.EventType)
+}
+
+// String returns a description of the event data, decoded according to its
+// type.
+func (e *Event) String() string {
+	switch e.EventType {
+	case EV_EFI_VARIABLE_DRIVER_CONFIG, EV_EFI_VARIABLE_BOOT, EV_EFI_VARIABLE_BOOT2, EV_EFI_VARIABLE_AUTHORITY:
+		return decodeVariable(e.Data)
+	case EV_EFI_BOOT_SERVICES_APPLICATION, EV_EFI_BOOT_SERVICES_DRIVER, EV_EFI_RUNTIME_SERVICES_DRIVER:
+		return decodeImageLoad(e.Data)
+	case EV_SEPARATOR:
+		return fmt.Sprintf("%x", e.Data)
+	case EV_NO_ACTION:
+		return printable(bytes.TrimRight(e.Data, "\x00"))
+	case EV_S_CRTM_VERSION:
+		return decodeUTF16(e.Data)
+	default:
+		return printable(e.Data)
+	}
+}
+
+func decodeUTF16(buf []byte) string {
+	var s []uint16
+
+	for i := 0; i+1 < len(buf); i += 2 {
+		c := binary.LittleEndian.Uint16(buf[i:])
+
+		if c == 0 {
+			break
+		}
+
+		s = append(s, c)
+	}
+
+	return string(utf16.Decode(s))
+}
+
+// printable returns the argument data as text when printable, or UTF-16
+// encoded text, otherwise its size.
+func printable(buf []byte) string {
+	isText := func(s string) bool {
+		for _, c := range s {
+			if c < 0x20 || c > 0x7e {
+				return false
+			}
+		}
+
+		return len(s) > 0
+	}
+
+	if s := strings.TrimRight(string(buf), "\x00"); isText(s) {
+		return s
+	}
+
+	if s := decodeUTF16(buf); isText(s) {
+		return s
+	}
+
+	return fmt.Sprintf("<%d bytes>", len(buf))
+}
+
+// decodeVariable decodes an UEFI_VARIABLE_DATA instance.
+func decodeVariable(buf []byte) string {
+	r := &reader{buf: buf}
+
+	guid := r.next(16)
+	nameLength := r.le64()
+	dataLength := r.le64()
+	name := r.next(int(nameLength) * 2)
+
+	if r.err != nil {
+		return printable(buf)
+	}
+
+	return fmt.Sprintf("%s %s (%d bytes)", formatGUID(guid), decodeUTF16(name), dataLength)
+}
+
+// decodeImageLoad decodes an UEFI_IMAGE_LOAD_EVENT instance.
+func decodeImageLoad(buf []byte) string {
+	var path []string
+
+	r := &reader{buf: buf}
+
+	r.le64()
+	length := r.le64()
+	r.le64()
+	devicePath := r.next(int(r.le64()))
+
+	if r.err != nil {
+		return printable(buf)
+	}
+
+	dp := &reader{buf: devicePath}
+
+	for len(dp.buf) >= 4 {
+		t := dp.uint8()
+		st := dp.uint8()
+		data := dp.next(int(dp.le16()) - 4)
+
+		if dp.err != nil || (t == 0x7f && st == 0xff) {
+			break
+		}
+
+		switch {
+		case t == 0x04 && st == 0x04: // Media Device Path / File Path
+			path = append(path, decodeUTF16(data))
+		default:
+			path = append(path, fmt.Sprintf("%d/%d", t, st))
+		}
+	}
+
+	return fmt.Sprintf("%s (%d bytes)", strings.Join(path, "/"), length)
+}
+
+// formatGUID returns the registry format string representation of an EFI
+// GUID.
+func formatGUID(g []byte) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(g[0:4]),
+		binary.LittleEndian.Uint16(g[4:6]),
+		binary.LittleEndian.Uint16(g[6:8]),
+		g[8:10],
+		g[10:])
+}
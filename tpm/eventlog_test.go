@@ -0,0 +1,273 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package tpm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"testing"
+)
+
+// testdata/eventlog.bin is a crypto agile log, with SHA1 and SHA256 banks,
+// following the OVMF event sequence: startup locality 3, CRTM version,
+// firmware blob, SecureBoot variable, PCR 0-7 separators and boot
+// application.
+const testLog = "testdata/eventlog.bin"
+
+// offset of the first TCG_PCR_EVENT2 in testLog
+const testLogHeaderSize = 32 + 16 + 4 + 4 + 4 + 2*4 + 1
+
+// size of each TCG_PCR_EVENT2 header in testLog
+const testEventHeaderSize = 4 + 4 + 4 + 2 + 20 + 2 + 32 + 4
+
+func readTestLog(t *testing.T) []byte {
+	buf, err := os.ReadFile(testLog)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf
+}
+
+func TestParseEventLog(t *testing.T) {
+	buf := readTestLog(t)
+	l, err := ParseEventLog(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	algs := []AlgorithmSize{{TPM_ALG_SHA1, 20}, {TPM_ALG_SHA256, 32}}
+
+	if len(l.SpecID.Algorithms) != len(algs) {
+		t.Fatalf("algorithms: got %v, want %v", l.SpecID.Algorithms, algs)
+	}
+
+	for i, a := range algs {
+		if l.SpecID.Algorithms[i] != a {
+			t.Errorf("algorithm %d: got %v, want %v", i, l.SpecID.Algorithms[i], a)
+		}
+	}
+
+	if l.SpecID.SpecVersionMajor != 2 || l.SpecID.UintnSize != 2 {
+		t.Errorf("unexpected Spec ID event %+v", l.SpecID)
+	}
+
+	if l.Locality != 3 {
+		t.Errorf("locality: got %d, want 3", l.Locality)
+	}
+
+	events := []struct {
+		pcr  uint32
+		typ  string
+		data string
+	}{
+		{0, "EV_NO_ACTION", "<17 bytes>"},
+		{0, "EV_S_CRTM_VERSION", "1.00"},
+		{0, "EV_EFI_PLATFORM_FIRMWARE_BLOB", "<16 bytes>"},
+		{7, "EV_EFI_VARIABLE_DRIVER_CONFIG", "8be4df61-93ca-11d2-aa0d-00e098032b8c SecureBoot (1 bytes)"},
+		{0, "EV_SEPARATOR", "00000000"},
+		{1, "EV_SEPARATOR", "00000000"},
+		{2, "EV_SEPARATOR", "00000000"},
+		{3, "EV_SEPARATOR", "00000000"},
+		{4, "EV_SEPARATOR", "00000000"},
+		{5, "EV_SEPARATOR", "00000000"},
+		{6, "EV_SEPARATOR", "00000000"},
+		{7, "EV_SEPARATOR", "00000000"},
+		{4, "EV_EFI_BOOT_SERVICES_APPLICATION", `\EFI\BOOT\BOOTX64.EFI (106496 bytes)`},
+	}
+
+	if len(l.Events) != len(events) {
+		t.Fatalf("events: got %d, want %d", len(l.Events), len(events))
+	}
+
+	for i, want := range events {
+		e := l.Events[i]
+
+		if e.PCRIndex != want.pcr || e.Type() != want.typ || e.String() != want.data {
+			t.Errorf("event %d: got %d %s %q, want %d %s %q", i, e.PCRIndex, e.Type(), e.String(), want.pcr, want.typ, want.data)
+		}
+
+		if len(e.Digest(TPM_ALG_SHA1)) != 20 || len(e.Digest(TPM_ALG_SHA256)) != 32 || e.Digest(TPM_ALG_SHA384) != nil {
+			t.Errorf("event %d: unexpected digests %v", i, e.Digests)
+		}
+	}
+
+	off := testLogHeaderSize
+
+	for i := range l.Events {
+		n, err := l.EventSize(buf[off : off+l.MaxEventHeaderSize()])
+
+		if err != nil {
+			t.Fatalf("event %d size: %v", i, err)
+		}
+
+		if want := testEventHeaderSize + len(l.Events[i].Data); n != want {
+			t.Errorf("event %d size: got %d, want %d", i, n, want)
+		}
+
+		off += n
+	}
+
+	if off != len(buf) {
+		t.Errorf("event sizes: got %d, want %d", off, len(buf))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	l, err := ParseEventLog(readTestLog(t))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	banks := l.Replay()
+
+	pcrs := map[uint16]map[int]string{
+		TPM_ALG_SHA1: {
+			0: "4556a24dd7bf2c633e0fffeadeb9a40539f6ad02",
+			4: "ada76a511b0c6b8cd1cedfc8575c219030e5f61b",
+			7: "d09d020cf9660f279cef0ee10c5e85c8a6e785ae",
+		},
+		TPM_ALG_SHA256: {
+			0: "a2388d11a7c0d758ee4b2a295918e8dbef6c8c16430e3c772fdbe285960e0fd9",
+			4: "01faf38b83f80cd5f0252bc8b0bdc8a7eea17c48f1518e9dd375209d87e6818e",
+			7: "25f01af5d39925c6c7fbcee8819de8a4e9e422dd831a7bb5bd52b2762ea928f9",
+		},
+	}
+
+	if len(banks) != len(pcrs) {
+		t.Fatalf("banks: got %d, want %d", len(banks), len(pcrs))
+	}
+
+	for alg, want := range pcrs {
+		bank := banks[alg]
+
+		// PCRs 0-7 have at least a separator event
+		if len(bank) != 8 {
+			t.Errorf("bank %#x: got %d PCRs, want 8", alg, len(bank))
+		}
+
+		for pcr, digest := range want {
+			if got := hex.EncodeToString(bank[pcr]); got != digest {
+				t.Errorf("bank %#x PCR %d: got %s, want %s", alg, pcr, got, digest)
+			}
+		}
+	}
+}
+
+func TestParseEventLogTruncated(t *testing.T) {
+	buf := readTestLog(t)
+
+	// event boundaries, where truncation yields a valid shorter log
+	boundaries := map[int]bool{}
+
+	l, err := ParseEventLog(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	off := testLogHeaderSize
+	boundaries[off] = true
+
+	for _, e := range l.Events {
+		off += testEventHeaderSize + len(e.Data)
+		boundaries[off] = true
+	}
+
+	for n := range len(buf) {
+		l, err := ParseEventLog(buf[:n])
+
+		switch {
+		case boundaries[n] && err != nil:
+			t.Errorf("length %d: unexpected error %v", n, err)
+		case !boundaries[n] && err == nil:
+			t.Errorf("length %d: expected error, got %d events", n, len(l.Events))
+		}
+	}
+}
+
+func TestParseEventLogMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(buf []byte) []byte
+	}{
+		{
+			name: "empty",
+			modify: func(buf []byte) []byte {
+				return nil
+			},
+		},
+		{
+			name: "header event type",
+			modify: func(buf []byte) []byte {
+				binary.LittleEndian.PutUint32(buf[4:], EV_POST_CODE)
+				return buf
+			},
+		},
+		{
+			name: "Spec ID signature",
+			modify: func(buf []byte) []byte {
+				copy(buf[32:], "Spec ID Event02")
+				return buf
+			},
+		},
+		{
+			name: "Spec ID digest size",
+			modify: func(buf []byte) []byte {
+				// SHA256 declared with SHA1 digest size
+				binary.LittleEndian.PutUint16(buf[32+16+8+4+4+2:], 20)
+				return buf
+			},
+		},
+		{
+			name: "header event size",
+			modify: func(buf []byte) []byte {
+				binary.LittleEndian.PutUint32(buf[28:], 0xffffffff)
+				return buf
+			},
+		},
+		{
+			name: "unknown event digest algorithm",
+			modify: func(buf []byte) []byte {
+				binary.LittleEndian.PutUint16(buf[testLogHeaderSize+12:], TPM_ALG_SHA384)
+				return buf
+			},
+		},
+		{
+			name: "event digest count",
+			modify: func(buf []byte) []byte {
+				binary.LittleEndian.PutUint32(buf[testLogHeaderSize+8:], 0xffffffff)
+				return buf
+			},
+		},
+		{
+			name: "event data size",
+			modify: func(buf []byte) []byte {
+				binary.LittleEndian.PutUint32(buf[testLogHeaderSize+testEventHeaderSize-4:], 0x7fffffff)
+				return buf
+			},
+		},
+		{
+			name: "trailing garbage",
+			modify: func(buf []byte) []byte {
+				return append(buf, bytes.Repeat([]byte{0xff}, 16)...)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseEventLog(tt.modify(readTestLog(t))); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package tpm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const sizeOfSelect = NumPCR / 8
+
+// PCRSelection returns a TPML_PCR_SELECTION instance for a single bank.
+func PCRSelection(alg uint16, pcrs []int) (buf []byte, err error) {
+	sel := make([]byte, sizeOfSelect)
+
+	for _, pcr := range pcrs {
+		if pcr < 0 || pcr >= NumPCR {
+			return nil, fmt.Errorf("invalid PCR index %d", pcr)
+		}
+
+		sel[pcr/8] |= 1 << (pcr % 8)
+	}
+
+	buf = binary.BigEndian.AppendUint32(buf, 1)
+	buf = binary.BigEndian.AppendUint16(buf, alg)
+	buf = append(buf, sizeOfSelect)
+	buf = append(buf, sel...)
+
+	return
+}
+
+// parsePCRSelection parses a TPML_PCR_SELECTION instance, returning the
+// selected PCRs for the argument bank.
+func parsePCRSelection(r *reader, alg uint16) (pcrs []int) {
+	count := int(r.uint32())
+
+	// each TPMS_PCR_SELECTION is at least 3 bytes
+	if r.err == nil && count > len(r.buf)/3 {
+		r.err = errors.New("invalid PCR selection count")
+	}
+
+	for i := 0; i < count && r.err == nil; i++ {
+		hash := r.uint16()
+		sel := r.next(int(r.uint8()))
+
+		if r.err != nil || hash != alg {
+			continue
+		}
+
+		for i, b := range sel {
+			for j := range 8 {
+				if b&(1<<j) != 0 {
+					pcrs = append(pcrs, i*8+j)
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// PCRRead calls TPM2_PCR_Read() until all requested PCR values for the
+// argument bank are returned.
+func (t *TPM) PCRRead(alg uint16, pcrs []int) (values map[int][]byte, err error) {
+	values = make(map[int][]byte)
+	pending := pcrs
+
+	for len(pending) > 0 {
+		params, err := PCRSelection(alg, pending)
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, r, err := t.execute(TPM_CC_PCR_Read, nil, nil, params, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// pcrUpdateCounter
+		r.uint32()
+
+		selected := parsePCRSelection(r, alg)
+
+		if count := int(r.uint32()); count != len(selected) {
+			return nil, errors.New("invalid PCR digest count")
+		}
+
+		for _, pcr := range selected {
+			values[pcr] = r.tpm2b()
+		}
+
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		if len(selected) == 0 {
+			return nil, errors.New("PCR bank unavailable")
+		}
+
+		// the TPM returns a limited number of digests for each call
+		var next []int
+
+		for _, pcr := range pending {
+			if _, ok := values[pcr]; !ok {
+				next = append(next, pcr)
+			}
+		}
+
+		if len(next) == len(pending) {
+			return nil, errors.New("PCR read returned none of the requested PCRs")
+		}
+
+		pending = next
+	}
+
+	return
+}
@@ -0,0 +1,78 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package tpm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// pcrReadTransport responds to TPM2_PCR_Read() with the argument PCRs,
+// regardless of the requested ones.
+type pcrReadTransport struct {
+	pcrs []int
+}
+
+func (tr *pcrReadTransport) SubmitCommand(cmd []byte) (res []byte, err error) {
+	sel, _ := PCRSelection(TPM_ALG_SHA256, tr.pcrs)
+
+	// pcrUpdateCounter, pcrSelectionOut
+	body := binary.BigEndian.AppendUint32(nil, 1)
+	body = append(body, sel...)
+
+	// pcrValues
+	body = binary.BigEndian.AppendUint32(body, uint32(len(tr.pcrs)))
+
+	for _, pcr := range tr.pcrs {
+		body = binary.BigEndian.AppendUint16(body, 32)
+		body = append(body, bytes.Repeat([]byte{byte(pcr)}, 32)...)
+	}
+
+	res = binary.BigEndian.AppendUint16(nil, TPM_ST_NO_SESSIONS)
+	res = binary.BigEndian.AppendUint32(res, uint32(headerSize+len(body)))
+	res = binary.BigEndian.AppendUint32(res, TPM_RC_SUCCESS)
+
+	return append(res, body...), nil
+}
+
+func TestPCRRead(t *testing.T) {
+	tpm := &TPM{Transport: &pcrReadTransport{pcrs: []int{0, 7}}}
+	values, err := tpm.PCRRead(TPM_ALG_SHA256, []int{0, 7})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pcr := range []int{0, 7} {
+		if !bytes.Equal(values[pcr], bytes.Repeat([]byte{byte(pcr)}, 32)) {
+			t.Errorf("unexpected PCR %d value %x", pcr, values[pcr])
+		}
+	}
+}
+
+func TestPCRReadNoProgress(t *testing.T) {
+	tpm := &TPM{Transport: &pcrReadTransport{pcrs: []int{4}}}
+
+	if _, err := tpm.PCRRead(TPM_ALG_SHA256, []int{0, 7}); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+func TestParsePCRSelectionMalformed(t *testing.T) {
+	for _, buf := range [][]byte{
+		// count exceeding the response
+		{0xff, 0xff, 0xff, 0xff, 0x00, 0x0b, 0x03},
+		// truncated selection
+		{0x00, 0x00, 0x00, 0x01, 0x00, 0x0b, 0x03, 0x01},
+	} {
+		r := &reader{buf: buf}
+
+		if pcrs := parsePCRSelection(r, TPM_ALG_SHA256); r.err == nil {
+			t.Errorf("%x: unexpected success, %v", buf, pcrs)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package tpm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// sealed object serialization magic
+const sealedMagic = 0x67627470 // gbtp
+
+const nonceSize = 16
+
+// MaxSealedDataSize represents the maximum size of sealed data
+// (TPM2B_SENSITIVE_DATA, MAX_SYM_DATA).
+const MaxSealedDataSize = 128
+
+// StartAuthSession calls TPM2_StartAuthSession() for an unbound and unsalted
+// policy session (or trial session for policy digest computation).
+func (t *TPM) StartAuthSession(trial bool) (handle uint32, err error) {
+	var params []byte
+
+	nonce := make([]byte, nonceSize)
+
+	if _, err = rand.Read(nonce); err != nil {
+		return
+	}
+
+	sessionType := uint8(TPM_SE_POLICY)
+
+	if trial {
+		sessionType = TPM_SE_TRIAL
+	}
+
+	params = appendTPM2B(params, nonce)
+	params = appendTPM2B(params, nil)
+	params = append(params, sessionType)
+	params = binary.BigEndian.AppendUint16(params, TPM_ALG_NULL)
+	params = binary.BigEndian.AppendUint16(params, TPM_ALG_SHA256)
+
+	handles, _, err := t.execute(TPM_CC_StartAuthSession, []uint32{TPM_RH_NULL, TPM_RH_NULL}, nil, params, 1)
+
+	if err != nil {
+		return
+	}
+
+	return handles[0], nil
+}
+
+// PolicyPCR calls TPM2_PolicyPCR() to bind the argument policy session to the
+// current values of the selected PCRs.
+func (t *TPM) PolicyPCR(session uint32, alg uint16, pcrs []int) (err error) {
+	var params []byte
+
+	sel, err := PCRSelection(alg, pcrs)
+
+	if err != nil {
+		return
+	}
+
+	params = appendTPM2B(params, nil)
+	params = append(params, sel...)
+
+	_, _, err = t.execute(TPM_CC_PolicyPCR, []uint32{session}, nil, params, 0)
+
+	return
+}
+
+// PolicyGetDigest calls TPM2_PolicyGetDigest().
+func (t *TPM) PolicyGetDigest(session uint32) (digest []byte, err error) {
+	_, r, err := t.execute(TPM_CC_PolicyGetDigest, []uint32{session}, nil, nil, 0)
+
+	if err != nil {
+		return
+	}
+
+	digest = r.tpm2b()
+
+	return digest, r.err
+}
+
+// CreatePrimary calls TPM2_CreatePrimary() to create an ECC NIST P-256
+// storage key under the owner hierarchy, which is expected to have an empty
+// authorization value.
+func (t *TPM) CreatePrimary() (handle uint32, err error) {
+	var public []byte
+	var params []byte
+
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_ECC)
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_SHA256)
+	public = binary.BigEndian.AppendUint32(public, fixedTPM|fixedParent|sensitiveDataOrigin|userWithAuth|noDA|restricted|decrypt)
+	public = appendTPM2B(public, nil)
+	// TPMS_ECC_PARMS
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_AES)
+	public = binary.BigEndian.AppendUint16(public, 128)
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_CFB)
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_NULL)
+	public = binary.BigEndian.AppendUint16(public, TPM_ECC_NIST_P256)
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_NULL)
+	// TPMS_ECC_POINT
+	public = appendTPM2B(public, nil)
+	public = appendTPM2B(public, nil)
+
+	// TPM2B_SENSITIVE_CREATE with empty userAuth and data
+	params = appendTPM2B(params, make([]byte, 4))
+	params = appendTPM2B(params, public)
+	// outsideInfo
+	params = appendTPM2B(params, nil)
+	// creationPCR
+	params = binary.BigEndian.AppendUint32(params, 0)
+
+	auth := &session{handle: TPM_RS_PW}
+	handles, _, err := t.execute(TPM_CC_CreatePrimary, []uint32{TPM_RH_OWNER}, auth, params, 1)
+
+	if err != nil {
+		return
+	}
+
+	return handles[0], nil
+}
+
+// Create calls TPM2_Create() to create a sealed data object, under the
+// argument parent, which can only be unsealed by satisfying the argument
+// policy digest.
+func (t *TPM) Create(parent uint32, policy []byte, data []byte) (private []byte, public []byte, err error) {
+	var sensitive []byte
+	var params []byte
+
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_KEYEDHASH)
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_SHA256)
+	public = binary.BigEndian.AppendUint32(public, fixedTPM|fixedParent|noDA)
+	public = appendTPM2B(public, policy)
+	// TPMS_KEYEDHASH_PARMS
+	public = binary.BigEndian.AppendUint16(public, TPM_ALG_NULL)
+	public = appendTPM2B(public, nil)
+
+	sensitive = appendTPM2B(sensitive, nil)
+	sensitive = appendTPM2B(sensitive, data)
+
+	params = appendTPM2B(params, sensitive)
+	params = appendTPM2B(params, public)
+	// outsideInfo
+	params = appendTPM2B(params, nil)
+	// creationPCR
+	params = binary.BigEndian.AppendUint32(params, 0)
+
+	auth := &session{handle: TPM_RS_PW}
+	_, r, err := t.execute(TPM_CC_Create, []uint32{parent}, auth, params, 0)
+
+	if err != nil {
+		return
+	}
+
+	private = r.tpm2b()
+	public = r.tpm2b()
+
+	return private, public, r.err
+}
+
+// Load calls TPM2_Load().
+func (t *TPM) Load(parent uint32, private []byte, public []byte) (handle uint32, err error) {
+	var params []byte
+
+	params = appendTPM2B(params, private)
+	params = appendTPM2B(params, public)
+
+	auth := &session{handle: TPM_RS_PW}
+	handles, _, err := t.execute(TPM_CC_Load, []uint32{parent}, auth, params, 1)
+
+	if err != nil {
+		return
+	}
+
+	return handles[0], nil
+}
+
+// Unseal calls TPM2_Unseal() with the argument policy session authorization.
+func (t *TPM) Unseal(handle uint32, policySession uint32) (data []byte, err error) {
+	auth := &session{handle: policySession}
+	_, r, err := t.execute(TPM_CC_Unseal, []uint32{handle}, auth, nil, 0)
+
+	if err != nil {
+		return
+	}
+
+	data = r.tpm2b()
+
+	return data, r.err
+}
+
+// SealedObject represents a TPM sealed data object bound to PCR values.
+type SealedObject struct {
+	// PCRs is the SHA-256 bank PCR selection for the object policy.
+	PCRs []int
+	// Private is the TPM2B_PRIVATE object buffer.
+	Private []byte
+	// Public is the TPM2B_PUBLIC object buffer.
+	Public []byte
+}
+
+// Bytes converts the sealed object to byte array format.
+func (s *SealedObject) Bytes() (buf []byte) {
+	var sel uint32
+
+	for _, pcr := range s.PCRs {
+		sel |= 1 << pcr
+	}
+
+	buf = binary.BigEndian.AppendUint32(buf, sealedMagic)
+	buf = binary.BigEndian.AppendUint32(buf, sel)
+	buf = appendTPM2B(buf, s.Private)
+	buf = appendTPM2B(buf, s.Public)
+
+	return
+}
+
+// ParseSealedObject parses a sealed object previously converted with
+// [SealedObject.Bytes].
+func ParseSealedObject(buf []byte) (s *SealedObject, err error) {
+	r := &reader{buf: buf}
+
+	if r.uint32() != sealedMagic {
+		return nil, errors.New("invalid sealed object")
+	}
+
+	s = &SealedObject{}
+	sel := r.uint32()
+
+	for i := range NumPCR {
+		if sel&(1<<i) != 0 {
+			s.PCRs = append(s.PCRs, i)
+		}
+	}
+
+	s.Private = r.tpm2b()
+	s.Public = r.tpm2b()
+
+	return s, r.err
+}
+
+func (t *TPM) policyPCR(trial bool, pcrs []int) (session uint32, err error) {
+	if session, err = t.StartAuthSession(trial); err != nil {
+		return
+	}
+
+	if err = t.PolicyPCR(session, TPM_ALG_SHA256, pcrs); err != nil {
+		t.FlushContext(session)
+		return 0, err
+	}
+
+	return
+}
+
+// SealPCR seals the argument data to the current values of the selected
+// SHA-256 bank PCRs.
+func (t *TPM) SealPCR(data []byte, pcrs []int) (s *SealedObject, err error) {
+	var policy []byte
+
+	if len(pcrs) == 0 {
+		return nil, errors.New("empty PCR selection")
+	}
+
+	if len(data) > MaxSealedDataSize {
+		return nil, fmt.Errorf("data size (%d) exceeds %d bytes limit", len(data), MaxSealedDataSize)
+	}
+
+	session, err := t.policyPCR(true, pcrs)
+
+	if err != nil {
+		return
+	}
+
+	policy, err = t.PolicyGetDigest(session)
+	t.FlushContext(session)
+
+	if err != nil {
+		return
+	}
+
+	srk, err := t.CreatePrimary()
+
+	if err != nil {
+		return
+	}
+
+	defer t.FlushContext(srk)
+
+	s = &SealedObject{
+		PCRs: pcrs,
+	}
+
+	if s.Private, s.Public, err = t.Create(srk, policy, data); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// UnsealPCR unseals the argument sealed object, the operation only succeeds
+// if the selected PCRs match the values at sealing time.
+func (t *TPM) UnsealPCR(s *SealedObject) (data []byte, err error) {
+	srk, err := t.CreatePrimary()
+
+	if err != nil {
+		return
+	}
+
+	defer t.FlushContext(srk)
+
+	handle, err := t.Load(srk, s.Private, s.Public)
+
+	if err != nil {
+		return
+	}
+
+	defer t.FlushContext(handle)
+
+	session, err := t.policyPCR(false, s.PCRs)
+
+	if err != nil {
+		return
+	}
+
+	// the session is flushed by the TPM as continueSession is not set
+	if data, err = t.Unseal(handle, session); err != nil {
+		t.FlushContext(session)
+	}
+
+	return
+}
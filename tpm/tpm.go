@@ -0,0 +1,276 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+// Package tpm implements a minimal Trusted Platform Module (TPM) 2.0 command
+// layer following the specifications at:
+//
+//	https://trustedcomputinggroup.org/resource/tpm-library-specification/
+package tpm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// TPM Structures - 6.9 TPM_ST (Structure Tags)
+const (
+	TPM_ST_NO_SESSIONS = 0x8001
+	TPM_ST_SESSIONS    = 0x8002
+)
+
+// TPM Structures - 6.5.2 TPM_CC Listing
+const (
+	TPM_CC_CreatePrimary    = 0x00000131
+	TPM_CC_Create           = 0x00000153
+	TPM_CC_Load             = 0x00000157
+	TPM_CC_Unseal           = 0x0000015e
+	TPM_CC_FlushContext     = 0x00000165
+	TPM_CC_StartAuthSession = 0x00000176
+	TPM_CC_GetRandom        = 0x0000017b
+	TPM_CC_PCR_Read         = 0x0000017e
+	TPM_CC_PolicyPCR        = 0x0000017f
+	TPM_CC_PolicyGetDigest  = 0x00000189
+)
+
+// TPM Structures - 6.3 TPM_ALG_ID
+const (
+	TPM_ALG_RSA       = 0x0001
+	TPM_ALG_SHA1      = 0x0004
+	TPM_ALG_AES       = 0x0006
+	TPM_ALG_KEYEDHASH = 0x0008
+	TPM_ALG_SHA256    = 0x000b
+	TPM_ALG_SHA384    = 0x000c
+	TPM_ALG_SHA512    = 0x000d
+	TPM_ALG_NULL      = 0x0010
+	TPM_ALG_SM3_256   = 0x0012
+	TPM_ALG_ECC       = 0x0023
+	TPM_ALG_CFB       = 0x0043
+)
+
+// TPM Structures - 7.4 TPM_RH (Permanent Handles)
+const (
+	TPM_RH_OWNER = 0x40000001
+	TPM_RH_NULL  = 0x40000007
+	TPM_RS_PW    = 0x40000009
+)
+
+// TPM Structures - 6.11 TPM_SE (Session Type)
+const (
+	TPM_SE_HMAC   = 0x00
+	TPM_SE_POLICY = 0x01
+	TPM_SE_TRIAL  = 0x03
+)
+
+// TPM Structures - 6.4 TPM_ECC_CURVE
+const TPM_ECC_NIST_P256 = 0x0003
+
+// TPM Structures - 8.3 TPMA_OBJECT (Object Attributes)
+const (
+	fixedTPM            = 1 << 1
+	fixedParent         = 1 << 4
+	sensitiveDataOrigin = 1 << 5
+	userWithAuth        = 1 << 6
+	noDA                = 1 << 10
+	restricted          = 1 << 16
+	decrypt             = 1 << 17
+)
+
+// TPM_RC_SUCCESS represents a successful TPM response code.
+const TPM_RC_SUCCESS = 0x000
+
+// NumPCR represents the number of PCRs for each bank.
+const NumPCR = 24
+
+const headerSize = 10
+
+// Transport represents a TPM 2.0 command submission interface (e.g.
+// [uefi.TCG2]).
+type Transport interface {
+	SubmitCommand(cmd []byte) (res []byte, err error)
+}
+
+// TPM represents a TPM 2.0 device.
+type TPM struct {
+	// Transport is the TPM command submission interface.
+	Transport Transport
+}
+
+// ResponseError represents a TPM response code error.
+type ResponseError struct {
+	// Command is the command code.
+	Command uint32
+	// Code is the response code.
+	Code uint32
+}
+
+// Error implements the error interface.
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("TPM command %#x error, response code %#x", e.Command, e.Code)
+}
+
+// session represents a TPMS_AUTH_COMMAND instance without nonce and hmac, as
+// only password and policy sessions without authValue are supported.
+type session struct {
+	handle     uint32
+	attributes uint8
+}
+
+func (s *session) bytes() (buf []byte) {
+	buf = binary.BigEndian.AppendUint32(buf, s.handle)
+	buf = appendTPM2B(buf, nil)
+	buf = append(buf, s.attributes)
+	buf = appendTPM2B(buf, nil)
+
+	return
+}
+
+func appendTPM2B(buf []byte, data []byte) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(data)))
+	return append(buf, data...)
+}
+
+// reader helps parsing TPM responses.
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) next(n int) (b []byte) {
+	if r.err != nil {
+		return
+	}
+
+	if n < 0 || len(r.buf) < n {
+		r.err = errors.New("invalid response length")
+		return
+	}
+
+	b = r.buf[:n]
+	r.buf = r.buf[n:]
+
+	return
+}
+
+func (r *reader) uint8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+
+	return 0
+}
+
+func (r *reader) tpm2b() (b []byte) {
+	return r.next(int(r.uint16()))
+}
+
+// execute marshals and submits a TPM command, the response handles and
+// parameters are returned.
+func (t *TPM) execute(cc uint32, handles []uint32, auth *session, params []byte, numHandles int) (out []uint32, r *reader, err error) {
+	var body []byte
+
+	if t.Transport == nil {
+		return nil, nil, errors.New("invalid transport")
+	}
+
+	tag := uint16(TPM_ST_NO_SESSIONS)
+
+	for _, h := range handles {
+		body = binary.BigEndian.AppendUint32(body, h)
+	}
+
+	if auth != nil {
+		tag = TPM_ST_SESSIONS
+		a := auth.bytes()
+		body = binary.BigEndian.AppendUint32(body, uint32(len(a)))
+		body = append(body, a...)
+	}
+
+	body = append(body, params...)
+
+	cmd := binary.BigEndian.AppendUint16(nil, tag)
+	cmd = binary.BigEndian.AppendUint32(cmd, uint32(headerSize+len(body)))
+	cmd = binary.BigEndian.AppendUint32(cmd, cc)
+	cmd = append(cmd, body...)
+
+	res, err := t.Transport.SubmitCommand(cmd)
+
+	if err != nil {
+		return
+	}
+
+	if len(res) < headerSize {
+		return nil, nil, errors.New("invalid response length")
+	}
+
+	if rc := binary.BigEndian.Uint32(res[6:10]); rc != TPM_RC_SUCCESS {
+		return nil, nil, &ResponseError{Command: cc, Code: rc}
+	}
+
+	r = &reader{
+		buf: res[headerSize:],
+	}
+
+	for range numHandles {
+		out = append(out, r.uint32())
+	}
+
+	if auth != nil {
+		r.buf = r.next(int(r.uint32()))
+	}
+
+	return out, r, r.err
+}
+
+// GetRandom calls TPM2_GetRandom() until the requested number of random
+// bytes is returned.
+func (t *TPM) GetRandom(n int) (buf []byte, err error) {
+	for len(buf) < n {
+		params := binary.BigEndian.AppendUint16(nil, uint16(n-len(buf)))
+
+		_, r, err := t.execute(TPM_CC_GetRandom, nil, nil, params, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		b := r.tpm2b()
+
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		if len(b) == 0 {
+			return nil, errors.New("empty random bytes")
+		}
+
+		buf = append(buf, b...)
+	}
+
+	return buf[:n], nil
+}
+
+// FlushContext calls TPM2_FlushContext().
+func (t *TPM) FlushContext(handle uint32) (err error) {
+	params := binary.BigEndian.AppendUint32(nil, handle)
+	_, _, err = t.execute(TPM_CC_FlushContext, nil, nil, params, 0)
+	return
+}
@@ -11,6 +11,14 @@ import (
 	"github.com/usbarmory/tamago/dma"
 )
 
+// EFI Configuration Table GUIDs
+var (
+	ACPI_TABLE_GUID        = MustParseGUID("eb9d2d30-2d88-11d3-9a16-0090273fc14d")
+	EFI_ACPI_20_TABLE_GUID = MustParseGUID("8868e871-e4f1-11d3-bc22-0080c73c8881")
+	SMBIOS_TABLE_GUID      = MustParseGUID("eb9d2d31-2d88-11d3-9a16-0090273fc14d")
+	SMBIOS3_TABLE_GUID     = MustParseGUID("f2fd1544-9794-4a2c-992e-e5bbcf20e394")
+)
+
 // Configuration represents an EFI Configuration Table.
 type ConfigurationTable struct {
 	GUID        GUID
@@ -6,11 +6,11 @@
 package uefi
 
 import (
-	"bytes"
-	"encoding/binary"
 	"io"
 	"time"
 	"unicode/utf16"
+
+	"github.com/usbarmory/go-boot/vt100"
 )
 
 // EFI ConOut offsets
@@ -27,6 +27,7 @@ const (
 // EFI ConIn offsets
 const (
 	readKeyStroke = 0x08
+	waitForKey    = 0x10
 )
 
 // EFI text attributes
@@ -60,8 +61,9 @@ const (
 	space = 0x20
 )
 
-// Control Sequence Introducer n D - CUB - Cursor Back
-var cub = []byte{0x1b, 0x5b, 0x44, 0x20, 0x1b, 0x5b, 0x44}
+// ReadTimeout represents the maximum time [Console.Read] waits for input
+// before returning.
+var ReadTimeout = 10 * time.Millisecond
 
 // OutputMode represents an EFI Simple Text Output Mode instance.
 type OutputMode struct {
@@ -94,8 +96,25 @@ type Console struct {
 
 	// In should be set to the EFI SystemTable ConIn address.
 	In uint64
+	// InEx can be set to the EFI Simple Text Input Ex Protocol address
+	// for the ConIn handle, to enable key shift and toggle state
+	// reporting.
+	InEx uint64
 	// Out should be set to the EFI SystemTable ConOut address.
 	Out uint64
+
+	// Boot, when set, enables event driven input through the ConIn
+	// WaitForKey event, otherwise input is polled.
+	Boot *BootServices
+
+	// KeyFilter, when set, is invoked on each key stroke to optionally
+	// replace its translation to console input (e.g. for hotkeys).
+	KeyFilter func(k *KeyData) (buf []byte, ok bool)
+
+	// translated input not yet read
+	pending []byte
+	// VT100 output sequence parser
+	vt vt100.Parser
 }
 
 // GetMode returns the EFI Simple Text Output Mode instance.
@@ -213,6 +232,54 @@ func (c *Console) Input(k *InputKey) (status uint64) {
 	)
 }
 
+// WaitForKey returns the EFI_SIMPLE_TEXT_INPUT_PROTOCOL.WaitForKey event, or
+// EFI_SIMPLE_TEXT_INPUT_EX_PROTOCOL.WaitForKeyEx when [Console.InEx] is set.
+func (c *Console) WaitForKey() (event uint64, err error) {
+	var ptr struct {
+		Event uint64
+	}
+
+	in := c.In
+
+	if c.InEx != 0 {
+		in = c.InEx
+	}
+
+	err = decode(&ptr, in+waitForKey)
+
+	return ptr.Event, err
+}
+
+// wait waits for a key stroke or for [ReadTimeout] to elapse, it returns
+// whether a key stroke is available.
+//
+// The WaitForKey event is checked rather than waited for, as
+// EFI_BOOT_SERVICES.WaitForEvent() would block the entire runtime, while
+// sleeping lets other goroutines run.
+func (c *Console) wait() bool {
+	if c.Boot == nil || c.In == 0 {
+		time.Sleep(ReadTimeout)
+		return false
+	}
+
+	event, err := c.WaitForKey()
+
+	if err != nil || event == 0 {
+		c.Boot = nil
+		return false
+	}
+
+	if ready, _ := c.Boot.CheckEvent(event); ready {
+		return true
+	}
+
+	time.Sleep(ReadTimeout)
+
+	ready, _ := c.Boot.CheckEvent(event)
+
+	return ready
+}
+
 // Output calls EFI_SIMPLE_TEXT_OUTPUT_PROTOCOL.OutputString().
 func (c *Console) Output(p []byte) (status uint64) {
 	if p[len(p)-1] != null {
@@ -231,47 +298,82 @@ func (c *Console) Output(p []byte) (status uint64) {
 	)
 }
 
-// Read available data to buffer from console.
+// ReadKey reads a key stroke through ReadKeyStrokeEx(), when available, or
+// ReadKeyStroke().
+func (c *Console) ReadKey(k *KeyData) (status uint64) {
+	if c.InEx != 0 {
+		return c.InputEx(k)
+	}
+
+	*k = KeyData{}
+
+	return c.Input(&k.Key)
+}
+
+// translate converts a key stroke to console input.
+func (c *Console) translate(k *KeyData) []byte {
+	if c.KeyFilter != nil {
+		if buf, ok := c.KeyFilter(k); ok {
+			return buf
+		}
+	}
+
+	r := k.Char()
+
+	switch {
+	case k.Key.ScanCode > 0:
+		return scanCode(k)
+	case r == null:
+		// partial key stroke (e.g. shift keys only)
+		return nil
+	case k.Control() && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'):
+		// C0 control code
+		return []byte{byte(r) & 0x1f}
+	default:
+		return []byte(string(r))
+	}
+}
+
+// Read available data to buffer from console, when no data is available the
+// function waits up to [ReadTimeout] for a key stroke.
 func (c *Console) Read(p []byte) (n int, err error) {
-	k := &InputKey{}
+	k := &KeyData{}
 
 	for n < len(p) {
-		status := c.Input(k)
+		if len(c.pending) > 0 {
+			m := copy(p[n:], c.pending)
+			c.pending = c.pending[m:]
+			n += m
+			continue
+		}
+
+		status := c.ReadKey(k)
 
 		switch {
 		case status&0xff == EFI_NOT_READY:
-			if n == 0 {
-				// avoid starving Go scheduler
-				time.Sleep(10 * time.Millisecond)
+			if n == 0 && c.wait() {
+				continue
 			}
 
 			return
 		case status != EFI_SUCCESS:
 			return n, parseStatus(status)
-		case k.ScanCode > 0:
-			binary.LittleEndian.PutUint16(p[n:], k.ScanCode)
-		default:
-			copy(p[n:], k.UnicodeChar[:])
 		}
 
-		n += 2
+		c.pending = c.translate(k)
 	}
 
 	return
 }
 
-// Write data from buffer to console.
-func (c *Console) Write(p []byte) (n int, err error) {
+// text outputs UTF-8 text to the console.
+func (c *Console) text(p []byte) (err error) {
 	var s []byte
 
 	if len(p) == 0 {
 		return
 	}
 
-	if len(p) == len(cub) && bytes.Equal(cub, p) {
-		p = []byte{bs, 0x00}
-	}
-
 	// we receive an UTF-8 string and can output UTF-16
 	b := utf16.Encode([]rune(string(p)))
 
@@ -292,8 +394,14 @@ func (c *Console) Write(p []byte) (n int, err error) {
 	}
 
 	if status := c.Output(s); status != EFI_SUCCESS {
-		return n, parseStatus(status)
+		return parseStatus(status)
 	}
 
 	return
 }
+
+// Write data from buffer to console, VT100 Control Sequence Introducer (CSI)
+// sequences for colors, cursor movement and erasing are interpreted.
+func (c *Console) Write(p []byte) (n int, err error) {
+	return len(p), c.vt.Write((*screen)(c), p)
+}
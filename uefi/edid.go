@@ -0,0 +1,53 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package uefi
+
+import (
+	"errors"
+)
+
+var (
+	EFI_EDID_DISCOVERED_PROTOCOL_GUID = MustParseGUID("1c0c34f6-d380-41fa-a049-8ad06c1a66aa")
+	EFI_EDID_ACTIVE_PROTOCOL_GUID     = MustParseGUID("bd8c1056-9f36-44ec-92a8-a6337f817986")
+)
+
+// maximum EDID size (base block and 255 extensions)
+const maxEDIDSize = 256 * 128
+
+// GetEDID locates the EFI EDID Active Protocol, or the EFI EDID Discovered
+// Protocol when active is false, and returns its EDID data.
+func (s *BootServices) GetEDID(active bool) (edid []byte, err error) {
+	var addr uint64
+
+	var data struct {
+		SizeOfEdid uint32
+		_          uint32
+		Edid       uint64
+	}
+
+	guid := EFI_EDID_DISCOVERED_PROTOCOL_GUID
+
+	if active {
+		guid = EFI_EDID_ACTIVE_PROTOCOL_GUID
+	}
+
+	if addr, err = s.LocateProtocol(guid); err != nil {
+		return
+	}
+
+	if err = decode(&data, addr); err != nil {
+		return
+	}
+
+	if data.SizeOfEdid == 0 || data.SizeOfEdid > maxEDIDSize {
+		return nil, errors.New("invalid EDID size")
+	}
+
+	edid = make([]byte, data.SizeOfEdid)
+	err = decode(edid, data.Edid)
+
+	return
+}
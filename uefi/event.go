@@ -0,0 +1,149 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package uefi
+
+import (
+	"time"
+)
+
+// EFI Boot Services offsets
+const (
+	createEvent  = 0x50
+	setTimer     = 0x58
+	waitForEvent = 0x60
+	signalEvent  = 0x68
+	closeEvent   = 0x70
+	checkEvent   = 0x78
+	stall        = 0xf8
+)
+
+// EFI Event types
+const (
+	EVT_TIMER                         = 0x80000000
+	EVT_RUNTIME                       = 0x40000000
+	EVT_NOTIFY_WAIT                   = 0x00000100
+	EVT_NOTIFY_SIGNAL                 = 0x00000200
+	EVT_SIGNAL_EXIT_BOOT_SERVICES     = 0x00000201
+	EVT_SIGNAL_VIRTUAL_ADDRESS_CHANGE = 0x60000202
+)
+
+// EFI Task Priority Levels
+const (
+	TPL_APPLICATION = 4
+	TPL_CALLBACK    = 8
+	TPL_NOTIFY      = 16
+	TPL_HIGH_LEVEL  = 31
+)
+
+// EFI Timer Delay types
+const (
+	TimerCancel = iota
+	TimerPeriodic
+	TimerRelative
+)
+
+// CreateEvent calls EFI_BOOT_SERVICES.CreateEvent(), notification functions
+// are not supported, therefore events can only be waited for or checked.
+func (s *BootServices) CreateEvent(eventType uint32, tpl uint64) (event uint64, err error) {
+	status := callService(s.base+createEvent,
+		[]uint64{
+			uint64(eventType),
+			tpl,
+			0,
+			0,
+			ptrval(&event),
+		},
+	)
+
+	return event, parseStatus(status)
+}
+
+// CloseEvent calls EFI_BOOT_SERVICES.CloseEvent().
+func (s *BootServices) CloseEvent(event uint64) (err error) {
+	status := callService(s.base+closeEvent,
+		[]uint64{
+			event,
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// SetTimer calls EFI_BOOT_SERVICES.SetTimer(), the trigger time is rounded
+// down to the 100ns units used by the firmware.
+func (s *BootServices) SetTimer(event uint64, timerType int, trigger time.Duration) (err error) {
+	status := callService(s.base+setTimer,
+		[]uint64{
+			event,
+			uint64(timerType),
+			uint64(trigger / 100),
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// WaitForEvent calls EFI_BOOT_SERVICES.WaitForEvent(), the index of the
+// signaled event is returned.
+//
+// The call blocks the entire runtime (including other goroutines) until an
+// event is signaled, a timer event should be included to bound the wait.
+func (s *BootServices) WaitForEvent(events ...uint64) (index int, err error) {
+	var i uint64
+
+	if len(events) == 0 {
+		return -1, parseStatus(EFI_INVALID_PARAMETER)
+	}
+
+	status := callService(s.base+waitForEvent,
+		[]uint64{
+			uint64(len(events)),
+			ptrval(&events[0]),
+			ptrval(&i),
+		},
+	)
+
+	return int(i), parseStatus(status)
+}
+
+// SignalEvent calls EFI_BOOT_SERVICES.SignalEvent().
+func (s *BootServices) SignalEvent(event uint64) (err error) {
+	status := callService(s.base+signalEvent,
+		[]uint64{
+			event,
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// CheckEvent calls EFI_BOOT_SERVICES.CheckEvent(), signaled events are
+// cleared to the waiting state.
+func (s *BootServices) CheckEvent(event uint64) (signaled bool, err error) {
+	status := callService(s.base+checkEvent,
+		[]uint64{
+			event,
+		},
+	)
+
+	if status&0xff == EFI_NOT_READY {
+		return false, nil
+	}
+
+	return status == EFI_SUCCESS, parseStatus(status)
+}
+
+// Stall calls EFI_BOOT_SERVICES.Stall(), the duration is rounded down to
+// microseconds.
+func (s *BootServices) Stall(d time.Duration) (err error) {
+	status := callService(s.base+stall,
+		[]uint64{
+			uint64(d.Microseconds()),
+		},
+	)
+
+	return parseStatus(status)
+}
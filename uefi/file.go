@@ -92,7 +92,7 @@ func (fi *fileInfo) decode(buf []byte) (name string, err error) {
 }
 
 // open calls EFI_FILE_PROTOCOL.Open().
-func (f *fileProtocol) open(handle uint64, name string, mode uint64) (o *fileProtocol, addr uint64, err error) {
+func (f *fileProtocol) open(handle uint64, name string, mode uint64, attr uint64) (o *fileProtocol, addr uint64, err error) {
 	fileName := toUTF16(name)
 
 	status := callService(ptrval(&f.Open),
@@ -101,7 +101,7 @@ func (f *fileProtocol) open(handle uint64, name string, mode uint64) (o *filePro
 			ptrval(&addr),
 			ptrval(&fileName[0]),
 			mode,
-			0,
+			attr,
 		},
 	)
 
@@ -133,6 +133,17 @@ func (f *fileProtocol) close(handle uint64) (err error) {
 	return parseStatus(status)
 }
 
+// delete calls EFI_FILE_PROTOCOL.Delete().
+func (f *fileProtocol) delete(handle uint64) (err error) {
+	status := callService(ptrval(&f.Delete),
+		[]uint64{
+			handle,
+		},
+	)
+
+	return parseStatus(status)
+}
+
 // read calls EFI_FILE_PROTOCOL.Read().
 func (f *fileProtocol) read(handle uint64, buf []byte) (n int, err error) {
 	size := uint64(len(buf))
@@ -156,6 +167,37 @@ func (f *fileProtocol) read(handle uint64, buf []byte) (n int, err error) {
 	return int(size), parseStatus(status)
 }
 
+// write calls EFI_FILE_PROTOCOL.Write().
+func (f *fileProtocol) write(handle uint64, buf []byte) (n int, err error) {
+	size := uint64(len(buf))
+
+	if size == 0 {
+		return 0, nil
+	}
+
+	status := callService(ptrval(&f.Write),
+		[]uint64{
+			handle,
+			ptrval(&size),
+			ptrval(&buf[0]),
+		},
+	)
+
+	return int(size), parseStatus(status)
+}
+
+// setPosition calls EFI_FILE_PROTOCOL.SetPosition().
+func (f *fileProtocol) setPosition(handle uint64, pos uint64) (err error) {
+	status := callService(ptrval(&f.SetPosition),
+		[]uint64{
+			handle,
+			pos,
+		},
+	)
+
+	return parseStatus(status)
+}
+
 // getInfo calls EFI_FILE SYSTEM_PROTOCOL.GetInfo().
 func (f *fileProtocol) getInfo(handle uint64, guid GUID) (info *fileInfo, name string, err error) {
 	buf := make([]byte, fileInfoSize+MaxFileName*2)
@@ -271,6 +313,16 @@ func (f *File) Read(b []byte) (n int, err error) {
 	return f.file.read(f.addr, b)
 }
 
+// Write writes len(b) bytes from b to the File. It returns the number of
+// bytes written and an error, if any.
+func (f *File) Write(b []byte) (n int, err error) {
+	if f.addr == 0 {
+		return 0, errors.New("invalid file instance")
+	}
+
+	return f.file.write(f.addr, b)
+}
+
 // Close closes the File, rendering it unusable for I/O.
 func (f *File) Close() (err error) {
 	if f.addr == 0 {
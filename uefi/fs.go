@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"strings"
 )
 
@@ -18,6 +19,9 @@ const (
 	MaxDirEntries = 65536
 )
 
+// EFI File Protocol position for end of file
+const endOfFile = 0xffffffffffffffff
+
 var (
 	EFI_LOADED_IMAGE_PROTOCOL_GUID             = MustParseGUID("5b1b31a1-9562-11d2-8e3f-00a0c969723b")
 	EFI_LOADED_IMAGE_DEVICE_PATH_PROTOCOL_GUID = MustParseGUID("09576e91-6d3f-11d2-8e39-00a0c969723b")
@@ -105,13 +109,76 @@ func (root *FS) Open(name string) (fs.File, error) {
 
 	name = strings.ReplaceAll(name, `/`, `\`)
 
-	if f.file, f.addr, err = root.volume.file.open(root.volume.addr, name, EFI_FILE_MODE_READ); err != nil {
+	if f.file, f.addr, err = root.volume.file.open(root.volume.addr, name, EFI_FILE_MODE_READ, 0); err != nil {
 		return nil, err
 	}
 
 	return fs.File(f), nil
 }
 
+// OpenFile opens the named file with specified flag ([os.O_RDONLY] etc.),
+// [File.Close] must be called to release any associated resources.
+func (root *FS) OpenFile(name string, flag int) (f *File, err error) {
+	mode := uint64(EFI_FILE_MODE_READ)
+
+	if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		mode |= EFI_FILE_MODE_WRITE
+	}
+
+	if flag&os.O_CREATE != 0 {
+		mode |= EFI_FILE_MODE_WRITE | EFI_FILE_MODE_CREATE
+	}
+
+	f = &File{
+		name: name,
+	}
+
+	if root.volume == nil || root.volume.file == nil || root.volume.addr == 0 {
+		return nil, errors.New("invalid file system instance")
+	}
+
+	name = strings.ReplaceAll(name, `/`, `\`)
+
+	if f.file, f.addr, err = root.volume.file.open(root.volume.addr, name, mode, 0); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case flag&os.O_TRUNC != 0:
+		// EFI File Protocol lacks truncation, re-create the file
+		if err = f.file.delete(f.addr); err != nil {
+			return nil, err
+		}
+
+		if f.file, f.addr, err = root.volume.file.open(root.volume.addr, name, mode|EFI_FILE_MODE_CREATE, 0); err != nil {
+			return nil, err
+		}
+	case flag&os.O_APPEND != 0:
+		if err = f.file.setPosition(f.addr, endOfFile); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// WriteFile writes data to the named file, creating it if necessary.
+func (root *FS) WriteFile(name string, data []byte) (err error) {
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+
+	if err != nil {
+		return
+	}
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return
+	}
+
+	return f.Close()
+}
+
 func (s *BootServices) loadImageHandle(imageHandle uint64) (image *loadedImage, err error) {
 	var addr uint64
 
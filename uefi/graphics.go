@@ -9,7 +9,9 @@ var EFI_GRAPHICS_OUTPUT_PROTOCOL_GUID = MustParseGUID("9042a9de-23dc-4a38-96fb-7
 
 // EFI Graphics Output Protocol offsets
 const (
-	blt = 0x10
+	queryGraphicsMode = 0x00
+	setGraphicsMode   = 0x08
+	blt               = 0x10
 )
 
 type BltOperation int
@@ -23,6 +25,15 @@ const (
 	EfiGraphicsOutputBltOperationMax
 )
 
+// EFI_GRAPHICS_PIXEL_FORMAT
+const (
+	PixelRedGreenBlueReserved8BitPerColor = iota
+	PixelBlueGreenRedReserved8BitPerColor
+	PixelBitMask
+	PixelBltOnly
+	PixelFormatMax
+)
+
 // ModeInformation represents an EFI Graphics Output Mode Information instance.
 type ModeInformation struct {
 	Version              uint32
@@ -36,6 +47,37 @@ type ModeInformation struct {
 	PixelsPerScanLine    uint32
 }
 
+// Masks returns the red, green, blue and reserved pixel bit masks, for
+// PixelBltOnly format (no physical frame buffer) all masks are zero.
+func (m *ModeInformation) Masks() (red, green, blue, reserved uint32) {
+	switch m.PixelFormat {
+	case PixelRedGreenBlueReserved8BitPerColor:
+		return 0x000000ff, 0x0000ff00, 0x00ff0000, 0xff000000
+	case PixelBlueGreenRedReserved8BitPerColor:
+		return 0x00ff0000, 0x0000ff00, 0x000000ff, 0xff000000
+	case PixelBitMask:
+		return m.RedMask, m.GreenMask, m.BlueMask, m.ReservedMask
+	default:
+		return
+	}
+}
+
+// Format returns the pixel format name.
+func (m *ModeInformation) Format() string {
+	switch m.PixelFormat {
+	case PixelRedGreenBlueReserved8BitPerColor:
+		return "RGBX"
+	case PixelBlueGreenRedReserved8BitPerColor:
+		return "BGRX"
+	case PixelBitMask:
+		return "bitmask"
+	case PixelBltOnly:
+		return "blt-only"
+	default:
+		return "invalid"
+	}
+}
+
 // ProtocolMode represents an EFI Graphics Output Protocol Mode instance.
 type ProtocolMode struct {
 	MaxMode         uint32
@@ -57,6 +99,7 @@ func (d *ProtocolMode) GetInfo() (m *ModeInformation, err error) {
 type GraphicsOutput struct {
 	base uint64
 	mode uint64
+	boot *BootServices
 }
 
 // GetMode returns the EFI Graphics Output Mode instance.
@@ -66,6 +109,55 @@ func (gop *GraphicsOutput) GetMode() (pm *ProtocolMode, err error) {
 	return
 }
 
+// QueryMode calls EFI_GRAPHICS_OUTPUT_PROTOCOL.QueryMode().
+func (gop *GraphicsOutput) QueryMode(mode uint32) (info *ModeInformation, err error) {
+	var size uint64
+	var addr uint64
+
+	if gop.base == 0 {
+		return nil, parseStatus(EFI_UNSUPPORTED)
+	}
+
+	status := callService(gop.base+queryGraphicsMode,
+		[]uint64{
+			gop.base,
+			uint64(mode),
+			ptrval(&size),
+			ptrval(&addr),
+		},
+	)
+
+	if err = parseStatus(status); err != nil {
+		return
+	}
+
+	info = &ModeInformation{}
+	err = decode(info, addr)
+
+	if gop.boot != nil {
+		gop.boot.FreePool(addr)
+	}
+
+	return
+}
+
+// SetMode calls EFI_GRAPHICS_OUTPUT_PROTOCOL.SetMode(), the frame buffer is
+// cleared by the firmware on mode change.
+func (gop *GraphicsOutput) SetMode(mode uint32) (err error) {
+	if gop.base == 0 {
+		return parseStatus(EFI_UNSUPPORTED)
+	}
+
+	status := callService(gop.base+setGraphicsMode,
+		[]uint64{
+			gop.base,
+			uint64(mode),
+		},
+	)
+
+	return parseStatus(status)
+}
+
 // Blt calls EFI_GRAPHICS_OUTPUT_PROTCOL.Blt().
 func (gop *GraphicsOutput) Blt(buf []byte, op BltOperation, srcX, srcY, dstX, dstY, width, height, delta uint64) (err error) {
 	if gop.base == 0 {
@@ -93,7 +185,9 @@ func (gop *GraphicsOutput) Blt(buf []byte, op BltOperation, srcX, srcY, dstX, ds
 // GetGraphicsOutput locates and returns the EFI Graphics Output Protocol
 // instance.
 func (s *BootServices) GetGraphicsOutput() (gop *GraphicsOutput, err error) {
-	gop = &GraphicsOutput{}
+	gop = &GraphicsOutput{
+		boot: s,
+	}
 
 	var data struct {
 		QueryMode uint64
@@ -0,0 +1,170 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package uefi
+
+var EFI_SIMPLE_TEXT_INPUT_EX_PROTOCOL_GUID = MustParseGUID("dd9e7534-7762-4698-8c14-f58517a625aa")
+
+// EFI Simple Text Input Ex Protocol offsets
+const (
+	readKeyStrokeEx     = 0x08
+	setState            = 0x18
+	registerKeyNotify   = 0x20
+	unregisterKeyNotify = 0x28
+)
+
+// EFI Scan Codes
+const (
+	SCAN_NULL = iota
+	SCAN_UP
+	SCAN_DOWN
+	SCAN_RIGHT
+	SCAN_LEFT
+	SCAN_HOME
+	SCAN_END
+	SCAN_INSERT
+	SCAN_DELETE
+	SCAN_PAGE_UP
+	SCAN_PAGE_DOWN
+	SCAN_F1
+	SCAN_F2
+	SCAN_F3
+	SCAN_F4
+	SCAN_F5
+	SCAN_F6
+	SCAN_F7
+	SCAN_F8
+	SCAN_F9
+	SCAN_F10
+	SCAN_F11
+	SCAN_F12
+	SCAN_ESC
+)
+
+// EFI Key Shift State
+const (
+	EFI_SHIFT_STATE_VALID     = 0x80000000
+	EFI_RIGHT_SHIFT_PRESSED   = 0x00000001
+	EFI_LEFT_SHIFT_PRESSED    = 0x00000002
+	EFI_RIGHT_CONTROL_PRESSED = 0x00000004
+	EFI_LEFT_CONTROL_PRESSED  = 0x00000008
+	EFI_RIGHT_ALT_PRESSED     = 0x00000010
+	EFI_LEFT_ALT_PRESSED      = 0x00000020
+	EFI_RIGHT_LOGO_PRESSED    = 0x00000040
+	EFI_LEFT_LOGO_PRESSED     = 0x00000080
+	EFI_MENU_KEY_PRESSED      = 0x00000100
+	EFI_SYS_REQ_PRESSED       = 0x00000200
+)
+
+// EFI Key Toggle State
+const (
+	EFI_TOGGLE_STATE_VALID = 0x80
+	EFI_KEY_STATE_EXPOSED  = 0x40
+	EFI_SCROLL_LOCK_ACTIVE = 0x01
+	EFI_NUM_LOCK_ACTIVE    = 0x02
+	EFI_CAPS_LOCK_ACTIVE   = 0x04
+)
+
+// KeyData represents an EFI Key Data descriptor.
+type KeyData struct {
+	Key         InputKey
+	ShiftState  uint32
+	ToggleState uint8
+	_           [3]byte
+}
+
+// Char returns the key Unicode character.
+func (k *KeyData) Char() rune {
+	return rune(uint16(k.Key.UnicodeChar[0]) | uint16(k.Key.UnicodeChar[1])<<8)
+}
+
+// Control returns whether either Control key is pressed.
+func (k *KeyData) Control() bool {
+	return k.ShiftState&EFI_SHIFT_STATE_VALID != 0 &&
+		k.ShiftState&(EFI_LEFT_CONTROL_PRESSED|EFI_RIGHT_CONTROL_PRESSED) != 0
+}
+
+// Alt returns whether either Alt key is pressed.
+func (k *KeyData) Alt() bool {
+	return k.ShiftState&EFI_SHIFT_STATE_VALID != 0 &&
+		k.ShiftState&(EFI_LEFT_ALT_PRESSED|EFI_RIGHT_ALT_PRESSED) != 0
+}
+
+// Shift returns whether either Shift key is pressed.
+func (k *KeyData) Shift() bool {
+	return k.ShiftState&EFI_SHIFT_STATE_VALID != 0 &&
+		k.ShiftState&(EFI_LEFT_SHIFT_PRESSED|EFI_RIGHT_SHIFT_PRESSED) != 0
+}
+
+// InputEx calls EFI_SIMPLE_TEXT_INPUT_EX_PROTOCOL.ReadKeyStrokeEx().
+func (c *Console) InputEx(k *KeyData) (status uint64) {
+	if c.InEx == 0 {
+		return EFI_UNSUPPORTED
+	}
+
+	return callService(c.InEx+readKeyStrokeEx,
+		[]uint64{
+			c.InEx,
+			ptrval(k),
+		},
+	)
+}
+
+// SetState calls EFI_SIMPLE_TEXT_INPUT_EX_PROTOCOL.SetState().
+func (c *Console) SetState(toggle uint8) error {
+	if c.InEx == 0 {
+		return parseStatus(EFI_UNSUPPORTED)
+	}
+
+	toggle |= EFI_TOGGLE_STATE_VALID
+
+	status := callService(c.InEx+setState,
+		[]uint64{
+			c.InEx,
+			ptrval(&toggle),
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// RegisterKeyNotify calls EFI_SIMPLE_TEXT_INPUT_EX_PROTOCOL.RegisterKeyNotify().
+//
+// The notification function is invoked directly by the firmware, therefore it
+// must be the address of native code following the UEFI calling convention
+// (Go functions cannot be used).
+func (c *Console) RegisterKeyNotify(k *KeyData, fn uint64) (handle uint64, err error) {
+	if c.InEx == 0 {
+		return 0, parseStatus(EFI_UNSUPPORTED)
+	}
+
+	status := callService(c.InEx+registerKeyNotify,
+		[]uint64{
+			c.InEx,
+			ptrval(k),
+			fn,
+			ptrval(&handle),
+		},
+	)
+
+	return handle, parseStatus(status)
+}
+
+// UnregisterKeyNotify calls
+// EFI_SIMPLE_TEXT_INPUT_EX_PROTOCOL.UnregisterKeyNotify().
+func (c *Console) UnregisterKeyNotify(handle uint64) error {
+	if c.InEx == 0 {
+		return parseStatus(EFI_UNSUPPORTED)
+	}
+
+	status := callService(c.InEx+unregisterKeyNotify,
+		[]uint64{
+			c.InEx,
+			handle,
+		},
+	)
+
+	return parseStatus(status)
+}
@@ -37,14 +37,28 @@ const (
 	getStatus      = 0x58
 	transmit       = 0x60
 	receive        = 0x68
+	waitForPacket  = 0x70
 )
 
 // TransmitTimeout represents the timeout for [SimpleNetwork.Transmit].
 var TransmitTimeout = 10 * time.Millisecond
 
+// ReceiveTimeout represents the maximum time [SimpleNetwork.Receive] waits
+// for a packet before returning.
+var ReceiveTimeout = 1 * time.Millisecond
+
+// PollInterval represents the interval between transmit status polls.
+var PollInterval = 50 * time.Microsecond
+
 // SimpleNetwork represents an EFI Simple Network Protocol instance.
 type SimpleNetwork struct {
 	base uint64
+	boot *BootServices
+
+	// transmit timeout timer event
+	timer uint64
+	// WaitForPacket event
+	packet uint64
 }
 
 // Start calls EFI_SIMPLE_NETWORK.Start().
@@ -157,9 +171,15 @@ func (sn *SimpleNetwork) GetStatus() (interruptStatus uint32, txBuf uintptr, err
 // Transmit calls EFI_SIMPLE_NETWORK.Transmit(), the function waits for
 // EFI_SIMPLE_NETWORK.GetStatus() to report a transmit interrupt within
 // [TransmitTimeout] before returning.
+//
+// The timeout is tracked with an EFI timer event, while waiting other
+// goroutines run for each [PollInterval].
 func (sn *SimpleNetwork) Transmit(buf []byte) (err error) {
 	var txBuf uintptr
-	var start time.Time
+
+	if err = sn.startTimer(); err != nil {
+		return
+	}
 
 	for {
 		status := callService(sn.base+transmit,
@@ -174,18 +194,20 @@ func (sn *SimpleNetwork) Transmit(buf []byte) (err error) {
 			},
 		)
 
-		if start.IsZero() {
-			start = time.Now()
-		}
+		if status&0xff != EFI_NOT_READY {
+			if err = parseStatus(status); err != nil {
+				return
+			}
 
-		if status&0xff == EFI_NOT_READY {
-			continue
+			break
 		}
 
-		if err = parseStatus(status); err != nil {
+		if err = sn.yield(); err != nil {
 			return
 		}
+	}
 
+	for {
 		if _, txBuf, err = sn.GetStatus(); err != nil {
 			return
 		}
@@ -194,18 +216,83 @@ func (sn *SimpleNetwork) Transmit(buf []byte) (err error) {
 			break
 		}
 
-		if time.Since(start) > TransmitTimeout {
-			return errors.New("timeout")
+		if err = sn.yield(); err != nil {
+			return
 		}
 	}
 
-	return
+	return sn.boot.SetTimer(sn.timer, TimerCancel, 0)
 }
 
-// Receive calls EFI_SIMPLE_NETWORK.Receive().
+// yield returns an error if the transmit timeout timer event has been
+// signaled, otherwise it sleeps for [PollInterval].
+func (sn *SimpleNetwork) yield() error {
+	if expired, err := sn.boot.CheckEvent(sn.timer); err != nil || expired {
+		return errors.New("timeout")
+	}
+
+	time.Sleep(PollInterval)
+
+	return nil
+}
+
+// startTimer arms the transmit timeout timer event.
+func (sn *SimpleNetwork) startTimer() (err error) {
+	if sn.boot == nil {
+		return errors.New("invalid boot services instance")
+	}
+
+	if sn.timer == 0 {
+		if sn.timer, err = sn.boot.CreateEvent(EVT_TIMER, TPL_APPLICATION); err != nil {
+			return
+		}
+	}
+
+	// clear any previous expiration
+	if _, err = sn.boot.CheckEvent(sn.timer); err != nil {
+		return
+	}
+
+	return sn.boot.SetTimer(sn.timer, TimerRelative, TransmitTimeout)
+}
+
+// ready returns whether the WaitForPacket event is signaled, or true when
+// the event is not available.
+func (sn *SimpleNetwork) ready() bool {
+	var err error
+
+	if sn.boot == nil {
+		return true
+	}
+
+	if sn.packet == 0 {
+		if sn.packet, err = sn.WaitForPacket(); err != nil || sn.packet == 0 {
+			return true
+		}
+	}
+
+	ready, err := sn.boot.CheckEvent(sn.packet)
+
+	return ready || err != nil
+}
+
+// Receive calls EFI_SIMPLE_NETWORK.Receive(), when no packet is available the
+// function waits up to [ReceiveTimeout] for the WaitForPacket event.
+//
+// The event is checked rather than waited for, as
+// EFI_BOOT_SERVICES.WaitForEvent() would block the entire runtime, while
+// sleeping lets other goroutines run.
 func (sn *SimpleNetwork) Receive(buf []byte) (n int, err error) {
 	size := uint64(len(buf))
 
+	if !sn.ready() {
+		time.Sleep(ReceiveTimeout)
+
+		if !sn.ready() {
+			return 0, nil
+		}
+	}
+
 	status := callService(sn.base+receive,
 		[]uint64{
 			sn.base,
@@ -225,9 +312,24 @@ func (sn *SimpleNetwork) Receive(buf []byte) (n int, err error) {
 	return int(size), parseStatus(status)
 }
 
+// WaitForPacket returns the EFI_SIMPLE_NETWORK.WaitForPacket event.
+func (sn *SimpleNetwork) WaitForPacket() (event uint64, err error) {
+	var ptr struct {
+		Event uint64
+	}
+
+	err = decode(&ptr, sn.base+waitForPacket)
+
+	return ptr.Event, err
+}
+
 // GetNetwork locates and returns the EFI Simple Network Protocol instance.
 func (s *BootServices) GetNetwork() (sn *SimpleNetwork, err error) {
-	sn = &SimpleNetwork{}
+	sn = &SimpleNetwork{
+		boot: s,
+	}
+
 	sn.base, err = s.LocateProtocol(EFI_SIMPLE_NETWORK_PROTOCOL_GUID)
+
 	return
 }
@@ -9,6 +9,7 @@ package uefi
 const (
 	allocatePages = 0x28
 	freePages     = 0x30
+	freePool      = 0x48
 )
 
 // EFI_ALLOCATE_TYPE
@@ -65,3 +66,14 @@ func (s *BootServices) FreePages(physicalAddress uint64, size int) error {
 
 	return parseStatus(status)
 }
+
+// FreePool calls EFI_BOOT_SERVICES.FreePool().
+func (s *BootServices) FreePool(buffer uint64) error {
+	status := callService(s.base+freePool,
+		[]uint64{
+			buffer,
+		},
+	)
+
+	return parseStatus(status)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package uefi
+
+var EFI_SIMPLE_POINTER_PROTOCOL_GUID = MustParseGUID("31878c87-0b75-11d5-9a4f-0090273fc14d")
+
+// EFI Simple Pointer Protocol offsets
+const (
+	resetPointer = 0x00
+	getState     = 0x08
+)
+
+// PointerState represents an EFI Simple Pointer State instance.
+type PointerState struct {
+	RelativeMovementX int32
+	RelativeMovementY int32
+	RelativeMovementZ int32
+	LeftButton        uint8
+	RightButton       uint8
+	_                 [2]byte
+}
+
+// PointerMode represents an EFI Simple Pointer Mode instance.
+type PointerMode struct {
+	// Resolution values are expressed in counts per millimeter, zero
+	// values indicate that the axis is not supported.
+	ResolutionX uint64
+	ResolutionY uint64
+	ResolutionZ uint64
+	LeftButton  uint8
+	RightButton uint8
+}
+
+// SimplePointer represents an EFI Simple Pointer Protocol instance.
+type SimplePointer struct {
+	base uint64
+	mode uint64
+}
+
+// GetMode returns the EFI Simple Pointer Mode instance.
+func (sp *SimplePointer) GetMode() (pm *PointerMode, err error) {
+	pm = &PointerMode{}
+	err = decode(pm, sp.mode)
+	return
+}
+
+// Reset calls EFI_SIMPLE_POINTER_PROTOCOL.Reset().
+func (sp *SimplePointer) Reset() (err error) {
+	status := callService(sp.base+resetPointer,
+		[]uint64{
+			sp.base,
+			0,
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// GetState calls EFI_SIMPLE_POINTER_PROTOCOL.GetState(), it returns false
+// when the pointer state did not change since the last call.
+func (sp *SimplePointer) GetState(state *PointerState) (changed bool, err error) {
+	status := callService(sp.base+getState,
+		[]uint64{
+			sp.base,
+			ptrval(state),
+		},
+	)
+
+	if status&0xff == EFI_NOT_READY {
+		return false, nil
+	}
+
+	return true, parseStatus(status)
+}
+
+// GetSimplePointer locates and returns the EFI Simple Pointer Protocol
+// instance.
+func (s *BootServices) GetSimplePointer() (sp *SimplePointer, err error) {
+	sp = &SimplePointer{}
+
+	var data struct {
+		Reset        uint64
+		GetState     uint64
+		WaitForInput uint64
+		Mode         uint64
+	}
+
+	if sp.base, err = s.LocateProtocol(EFI_SIMPLE_POINTER_PROTOCOL_GUID); err != nil {
+		return
+	}
+
+	if err = decode(&data, sp.base); err != nil {
+		return
+	}
+
+	sp.mode = data.Mode
+
+	return
+}
@@ -5,12 +5,27 @@
 
 package uefi
 
+import (
+	"errors"
+)
+
 // EFI Boot Services offsets
 const (
-	handleProtocol = 0x098
-	locateProtocol = 0x140
+	handleProtocol     = 0x098
+	locateHandleBuffer = 0x138
+	locateProtocol     = 0x140
+)
+
+// EFI_LOCATE_SEARCH_TYPE
+const (
+	AllHandles = iota
+	ByRegisterNotify
+	ByProtocol
 )
 
+// maximum number of handles returned by LocateHandleBuffer()
+const maxHandles = 1024
+
 // HandleProtocol calls EFI_BOOT_SERVICES.HandleProtocol().
 func (s *BootServices) HandleProtocol(handle uint64, guid GUID) (addr uint64, err error) {
 	status := callService(s.base+handleProtocol,
@@ -36,3 +51,35 @@ func (s *BootServices) LocateProtocol(guid GUID) (addr uint64, err error) {
 
 	return addr, parseStatus(status)
 }
+
+// LocateHandleBuffer calls EFI_BOOT_SERVICES.LocateHandleBuffer() to return
+// all handles supporting the argument protocol.
+func (s *BootServices) LocateHandleBuffer(guid GUID) (handles []uint64, err error) {
+	var n uint64
+	var addr uint64
+
+	status := callService(s.base+locateHandleBuffer,
+		[]uint64{
+			ByProtocol,
+			ptrval(&guid[0]),
+			0,
+			ptrval(&n),
+			ptrval(&addr),
+		},
+	)
+
+	if err = parseStatus(status); err != nil {
+		return
+	}
+
+	defer s.FreePool(addr)
+
+	if n == 0 || n > maxHandles {
+		return nil, errors.New("invalid handle count")
+	}
+
+	handles = make([]uint64, n)
+	err = decode(handles, addr)
+
+	return
+}
@@ -0,0 +1,225 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package uefi
+
+import (
+	"runtime"
+)
+
+var EFI_SERIAL_IO_PROTOCOL_GUID = MustParseGUID("bb25cf6f-f1d4-11d2-9a0c-0090273fc1fd")
+
+// EFI Serial I/O Protocol offsets
+const (
+	resetSerial   = 0x08
+	setAttributes = 0x10
+	getControl    = 0x20
+	writeSerial   = 0x28
+	readSerial    = 0x30
+)
+
+// EFI_PARITY_TYPE
+const (
+	DefaultParity = iota
+	NoParity
+	EvenParity
+	OddParity
+	MarkParity
+	SpaceParity
+)
+
+// EFI_STOP_BITS_TYPE
+const (
+	DefaultStopBits = iota
+	OneStopBit
+	OneFiveStopBits
+	TwoStopBits
+)
+
+// EFI Serial I/O Protocol control bits
+const (
+	EFI_SERIAL_DATA_TERMINAL_READY          = 0x0001
+	EFI_SERIAL_REQUEST_TO_SEND              = 0x0002
+	EFI_SERIAL_CLEAR_TO_SEND                = 0x0010
+	EFI_SERIAL_DATA_SET_READY               = 0x0020
+	EFI_SERIAL_RING_INDICATE                = 0x0040
+	EFI_SERIAL_CARRIER_DETECT               = 0x0080
+	EFI_SERIAL_INPUT_BUFFER_EMPTY           = 0x0100
+	EFI_SERIAL_OUTPUT_BUFFER_EMPTY          = 0x0200
+	EFI_SERIAL_HARDWARE_LOOPBACK_ENABLE     = 0x1000
+	EFI_SERIAL_SOFTWARE_LOOPBACK_ENABLE     = 0x2000
+	EFI_SERIAL_HARDWARE_FLOW_CONTROL_ENABLE = 0x4000
+)
+
+// SerialMode represents an EFI Serial I/O Mode instance.
+type SerialMode struct {
+	ControlMask      uint32
+	Timeout          uint32
+	BaudRate         uint64
+	ReceiveFifoDepth uint32
+	DataBits         uint32
+	Parity           uint32
+	StopBits         uint32
+}
+
+// SerialIO represents an EFI Serial I/O Protocol instance, it implements
+// io.ReadWriter for use as console.
+type SerialIO struct {
+	// Handle represents the device handle
+	Handle uint64
+
+	base uint64
+	mode uint64
+}
+
+// GetMode returns the EFI Serial I/O Mode instance.
+func (sio *SerialIO) GetMode() (m *SerialMode, err error) {
+	m = &SerialMode{}
+	err = decode(m, sio.mode)
+	return
+}
+
+// Reset calls EFI_SERIAL_IO_PROTOCOL.Reset().
+func (sio *SerialIO) Reset() (err error) {
+	status := callService(sio.base+resetSerial,
+		[]uint64{
+			sio.base,
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// SetAttributes calls EFI_SERIAL_IO_PROTOCOL.SetAttributes(), zero values
+// select the device defaults.
+func (sio *SerialIO) SetAttributes(baudRate uint64, receiveFifoDepth uint32, timeout uint32, parity uint32, dataBits uint8, stopBits uint32) (err error) {
+	status := callService(sio.base+setAttributes,
+		[]uint64{
+			sio.base,
+			baudRate,
+			uint64(receiveFifoDepth),
+			uint64(timeout),
+			uint64(parity),
+			uint64(dataBits),
+			uint64(stopBits),
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// GetControl calls EFI_SERIAL_IO_PROTOCOL.GetControl().
+func (sio *SerialIO) GetControl() (control uint32, err error) {
+	status := callService(sio.base+getControl,
+		[]uint64{
+			sio.base,
+			ptrval(&control),
+		},
+	)
+
+	return control, parseStatus(status)
+}
+
+// Read calls EFI_SERIAL_IO_PROTOCOL.Read() for available input, to avoid
+// blocking until the device timeout it returns immediately when no input is
+// pending.
+func (sio *SerialIO) Read(p []byte) (n int, err error) {
+	var control uint32
+
+	for n < len(p) {
+		if control, err = sio.GetControl(); err != nil {
+			return
+		}
+
+		if control&EFI_SERIAL_INPUT_BUFFER_EMPTY != 0 {
+			break
+		}
+
+		size := uint64(1)
+
+		status := callService(sio.base+readSerial,
+			[]uint64{
+				sio.base,
+				ptrval(&size),
+				ptrval(&p[n]),
+			},
+		)
+
+		if status&0xff == EFI_TIMEOUT || size == 0 {
+			break
+		}
+
+		if err = parseStatus(status); err != nil {
+			return
+		}
+
+		n += int(size)
+	}
+
+	if n == 0 {
+		runtime.Gosched()
+	}
+
+	return
+}
+
+// Write calls EFI_SERIAL_IO_PROTOCOL.Write().
+func (sio *SerialIO) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return
+	}
+
+	size := uint64(len(p))
+
+	status := callService(sio.base+writeSerial,
+		[]uint64{
+			sio.base,
+			ptrval(&size),
+			ptrval(&p[0]),
+		},
+	)
+
+	return int(size), parseStatus(status)
+}
+
+// GetSerialIO locates and returns all EFI Serial I/O Protocol instances.
+func (s *BootServices) GetSerialIO() (devices []*SerialIO, err error) {
+	var handles []uint64
+
+	var data struct {
+		Revision      uint32
+		_             uint32
+		Reset         uint64
+		SetAttributes uint64
+		SetControl    uint64
+		GetControl    uint64
+		Write         uint64
+		Read          uint64
+		Mode          uint64
+	}
+
+	if handles, err = s.LocateHandleBuffer(EFI_SERIAL_IO_PROTOCOL_GUID); err != nil {
+		return
+	}
+
+	for _, handle := range handles {
+		sio := &SerialIO{
+			Handle: handle,
+		}
+
+		if sio.base, err = s.HandleProtocol(handle, EFI_SERIAL_IO_PROTOCOL_GUID); err != nil {
+			return
+		}
+
+		if err = decode(&data, sio.base); err != nil {
+			return
+		}
+
+		sio.mode = data.Mode
+		devices = append(devices, sio)
+	}
+
+	return
+}
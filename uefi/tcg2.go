@@ -0,0 +1,262 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package uefi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var EFI_TCG2_PROTOCOL_GUID = MustParseGUID("607f766c-7455-42be-930b-e4d76db2720f")
+
+// EFI TCG2 Protocol offsets
+const (
+	getCapability      = 0x00
+	getEventLog        = 0x08
+	hashLogExtendEvent = 0x10
+	submitCommand      = 0x18
+)
+
+// TCG2 response buffer size
+const maxResponseSize = 4096
+
+const (
+	EFI_TCG2_EVENT_LOG_FORMAT_TCG_1_2 = 0x00000001
+	EFI_TCG2_EVENT_LOG_FORMAT_TCG_2   = 0x00000002
+
+	EFI_TCG2_EXTEND_ONLY          = 0x0000000000000001
+	PE_COFF_IMAGE                 = 0x0000000000000010
+	EFI_TCG2_EVENT_HEADER_VERSION = 1
+
+	EFI_TCG2_BOOT_HASH_ALG_SHA1    = 0x00000001
+	EFI_TCG2_BOOT_HASH_ALG_SHA256  = 0x00000002
+	EFI_TCG2_BOOT_HASH_ALG_SHA384  = 0x00000004
+	EFI_TCG2_BOOT_HASH_ALG_SHA512  = 0x00000008
+	EFI_TCG2_BOOT_HASH_ALG_SM3_256 = 0x00000010
+)
+
+// TCG PC Client Platform Firmware Profile Specification
+// Version 1.05 - 10.4.1 Event Types
+const (
+	EV_IPL                           = 0x0000000d
+	EV_EFI_BOOT_SERVICES_APPLICATION = 0x80000003
+)
+
+// TCG2Version represents an EFI_TCG2_VERSION instance.
+type TCG2Version struct {
+	Major uint8
+	Minor uint8
+}
+
+// TCG2Capability represents an EFI_TCG2_BOOT_SERVICE_CAPABILITY instance.
+type TCG2Capability struct {
+	Size                uint8
+	StructureVersion    TCG2Version
+	ProtocolVersion     TCG2Version
+	_                   [3]byte
+	HashAlgorithmBitmap uint32
+	SupportedEventLogs  uint32
+	TPMPresentFlag      uint8
+	_                   uint8
+	MaxCommandSize      uint16
+	MaxResponseSize     uint16
+	_                   [2]byte
+	ManufacturerID      uint32
+	NumberOfPCRBanks    uint32
+	ActivePCRBanks      uint32
+}
+
+// TCG2EventHeader represents an EFI_TCG2_EVENT_HEADER instance.
+type TCG2EventHeader struct {
+	HeaderSize    uint32
+	HeaderVersion uint16
+	PCRIndex      uint32
+	EventType     uint32
+}
+
+// TCG2Event represents an EFI_TCG2_EVENT instance.
+type TCG2Event struct {
+	Header TCG2EventHeader
+	Event  []byte
+}
+
+// Bytes converts the descriptor structure to byte array format.
+func (e *TCG2Event) Bytes() []byte {
+	buf := new(bytes.Buffer)
+
+	e.Header.HeaderSize = uint32(binary.Size(e.Header))
+	e.Header.HeaderVersion = EFI_TCG2_EVENT_HEADER_VERSION
+
+	size := uint32(4 + int(e.Header.HeaderSize) + len(e.Event))
+
+	binary.Write(buf, binary.LittleEndian, size)
+	binary.Write(buf, binary.LittleEndian, e.Header)
+	buf.Write(e.Event)
+
+	return buf.Bytes()
+}
+
+// ImageLoadEvent represents an UEFI_IMAGE_LOAD_EVENT instance.
+type ImageLoadEvent struct {
+	ImageLocationInMemory uint64
+	ImageLengthInMemory   uint64
+	ImageLinkTimeAddress  uint64
+	DevicePath            []byte
+}
+
+// NewImageLoadEvent returns the UEFI_IMAGE_LOAD_EVENT instance for an image
+// at its current memory location.
+func NewImageLoadEvent(image []byte) *ImageLoadEvent {
+	e := &ImageLoadEvent{
+		ImageLengthInMemory: uint64(len(image)),
+	}
+
+	if len(image) > 0 {
+		e.ImageLocationInMemory = ptrval(&image[0])
+	}
+
+	return e
+}
+
+// Bytes converts the descriptor structure to byte array format.
+func (e *ImageLoadEvent) Bytes() []byte {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.LittleEndian, e.ImageLocationInMemory)
+	binary.Write(buf, binary.LittleEndian, e.ImageLengthInMemory)
+	binary.Write(buf, binary.LittleEndian, e.ImageLinkTimeAddress)
+	binary.Write(buf, binary.LittleEndian, uint64(len(e.DevicePath)))
+	buf.Write(e.DevicePath)
+
+	return buf.Bytes()
+}
+
+// EventLog represents the location of an EFI TCG2 event log.
+type EventLog struct {
+	// Format is the event log format.
+	Format uint32
+	// Location is the pointer to the first event log entry.
+	Location uint64
+	// LastEntry is the pointer to the last event log entry.
+	LastEntry uint64
+	// Truncated indicates whether the event log is missing entries.
+	Truncated bool
+}
+
+// TCG2 represents an EFI TCG2 Protocol instance.
+type TCG2 struct {
+	base uint64
+}
+
+// GetCapability calls EFI_TCG2_PROTOCOL.GetCapability().
+func (tcg *TCG2) GetCapability() (c *TCG2Capability, err error) {
+	c = &TCG2Capability{}
+	c.Size = uint8(binary.Size(c))
+
+	buf, _ := marshalBinary(c)
+
+	status := callService(tcg.base+getCapability,
+		[]uint64{
+			tcg.base,
+			ptrval(&buf[0]),
+		},
+	)
+
+	if err = parseStatus(status); err != nil {
+		return
+	}
+
+	err = unmarshalBinary(buf, c)
+
+	return
+}
+
+// GetEventLog calls EFI_TCG2_PROTOCOL.GetEventLog().
+func (tcg *TCG2) GetEventLog(format uint32) (log *EventLog, err error) {
+	var truncated uint8
+
+	log = &EventLog{
+		Format: format,
+	}
+
+	status := callService(tcg.base+getEventLog,
+		[]uint64{
+			tcg.base,
+			uint64(format),
+			ptrval(&log.Location),
+			ptrval(&log.LastEntry),
+			ptrval(&truncated),
+		},
+	)
+
+	log.Truncated = truncated != 0
+
+	return log, parseStatus(status)
+}
+
+// HashLogExtendEvent calls EFI_TCG2_PROTOCOL.HashLogExtendEvent(), the
+// argument data is hashed and extended to the PCR index set in the event
+// header, the event is then logged.
+func (tcg *TCG2) HashLogExtendEvent(flags uint64, data []byte, event *TCG2Event) (err error) {
+	if len(data) == 0 {
+		return errors.New("invalid data")
+	}
+
+	buf := event.Bytes()
+
+	status := callService(tcg.base+hashLogExtendEvent,
+		[]uint64{
+			tcg.base,
+			flags,
+			ptrval(&data[0]),
+			uint64(len(data)),
+			ptrval(&buf[0]),
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// SubmitCommand calls EFI_TCG2_PROTOCOL.SubmitCommand(), the argument must be
+// a marshaled TPM command and the TPM response is returned.
+func (tcg *TCG2) SubmitCommand(cmd []byte) (res []byte, err error) {
+	if len(cmd) == 0 {
+		return nil, errors.New("invalid command")
+	}
+
+	res = make([]byte, maxResponseSize)
+
+	status := callService(tcg.base+submitCommand,
+		[]uint64{
+			tcg.base,
+			uint64(len(cmd)),
+			ptrval(&cmd[0]),
+			uint64(len(res)),
+			ptrval(&res[0]),
+		},
+	)
+
+	if err = parseStatus(status); err != nil {
+		return nil, err
+	}
+
+	// TPM response header: tag (2 bytes), size (4 bytes), code (4 bytes)
+	size := int(binary.BigEndian.Uint32(res[2:6]))
+
+	if size < 10 || size > len(res) {
+		return nil, errors.New("invalid response size")
+	}
+
+	return res[:size], nil
+}
+
+// GetTCG2 locates and returns the EFI TCG2 Protocol instance.
+func (s *BootServices) GetTCG2() (tcg *TCG2, err error) {
+	tcg = &TCG2{}
+	tcg.base, err = s.LocateProtocol(EFI_TCG2_PROTOCOL_GUID)
+	return
+}
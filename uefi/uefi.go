@@ -57,6 +57,10 @@ func ptrval(ptr any) uint64 {
 		p = unsafe.Pointer(v)
 	case *InputKey:
 		p = unsafe.Pointer(v)
+	case *KeyData:
+		p = unsafe.Pointer(v)
+	case *PointerState:
+		p = unsafe.Pointer(v)
 	default:
 		panic("internal error, invalid ptrval")
 	}
@@ -143,17 +147,21 @@ func (s *Services) Init(imageHandle uint64, systemTable uint64) (err error) {
 		return errors.New("EFI System Table pointer is invalid")
 	}
 
+	s.Boot = &BootServices{
+		base:        s.SystemTable.BootServices,
+		imageHandle: imageHandle,
+	}
+
 	s.Console = &Console{
 		ForceLine:   true,
 		ReplaceTabs: 8,
 		In:          s.SystemTable.ConIn,
 		Out:         s.SystemTable.ConOut,
+		Boot:        s.Boot,
 	}
 
-	s.Boot = &BootServices{
-		base:        s.SystemTable.BootServices,
-		imageHandle: imageHandle,
-	}
+	// Simple Text Input Ex is preferred for input, when available
+	s.Console.InEx, _ = s.Boot.HandleProtocol(s.SystemTable.ConsoleInHandle, EFI_SIMPLE_TEXT_INPUT_EX_PROTOCOL_GUID)
 
 	s.Runtime = &RuntimeServices{
 		base: s.SystemTable.RuntimeServices,
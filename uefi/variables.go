@@ -16,6 +16,7 @@ var EFI_GLOBAL_VARIABLE_GUID = MustParseGUID("8be4df61-93ca-11d2-aa0d-00e098032b
 const (
 	getVariable         = 0x48
 	getNextVariableName = 0x50
+	setVariable         = 0x58
 )
 
 // VariableAttributes represents the attributes of a UEFI variable.
@@ -31,6 +32,25 @@ type VariableAttributes struct {
 	EnhancedAuthAccess       bool
 }
 
+func (a *VariableAttributes) bits() (attributes uint32) {
+	for i, set := range []bool{
+		a.NonVolatile,
+		a.BootServiceAccess,
+		a.RuntimeServiceAccess,
+		a.HardwareErrorRecord,
+		a.AuthWriteAccess,
+		a.TimeBasedAuthWriteAccess,
+		a.AppendWrite,
+		a.EnhancedAuthAccess,
+	} {
+		if set {
+			attributes |= 1 << i
+		}
+	}
+
+	return
+}
+
 // GetVariable calls EFI_RUNTIME_SERVICES.GetVariable().
 // See: https://uefi.org/specs/UEFI/2.11/08_Services_Runtime_Services.html#getvariable
 func (s *RuntimeServices) GetVariable(name string, guid GUID, withData bool) (attr VariableAttributes, data []byte, err error) {
@@ -128,3 +148,28 @@ func (s *RuntimeServices) GetNextVariableName(name *string, guid *GUID) (err err
 
 	return
 }
+
+// SetVariable calls EFI_RUNTIME_SERVICES.SetVariable(), empty data deletes
+// the variable.
+// See: https://uefi.org/specs/UEFI/2.11/08_Services_Runtime_Services.html#setvariable
+func (s *RuntimeServices) SetVariable(name string, guid GUID, attr VariableAttributes, data []byte) (err error) {
+	var ptr uint64
+
+	nameUTF16 := toUTF16(name)
+
+	if len(data) > 0 {
+		ptr = ptrval(&data[0])
+	}
+
+	status := callService(s.base+setVariable,
+		[]uint64{
+			ptrval(&nameUTF16[0]),
+			ptrval(&guid[0]),
+			uint64(attr.bits()),
+			uint64(len(data)),
+			ptr,
+		},
+	)
+
+	return parseStatus(status)
+}
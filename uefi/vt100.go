@@ -0,0 +1,211 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+package uefi
+
+import (
+	"github.com/usbarmory/go-boot/vt100"
+)
+
+// EFI ConOut offsets
+const (
+	setCursorPosition = 0x38
+)
+
+// DefaultAttribute represents the console text attribute set on VT100 Select
+// Graphic Rendition (SGR) reset.
+var DefaultAttribute = EFI_WHITE | EFI_BLACK<<4
+
+// VT100 input sequences for EFI scan codes
+var vt100Keys = map[uint16]string{
+	SCAN_UP:        "\x1b[A",
+	SCAN_DOWN:      "\x1b[B",
+	SCAN_RIGHT:     "\x1b[C",
+	SCAN_LEFT:      "\x1b[D",
+	SCAN_HOME:      "\x1b[H",
+	SCAN_END:       "\x1b[F",
+	SCAN_INSERT:    "\x1b[2~",
+	SCAN_DELETE:    "\x1b[3~",
+	SCAN_PAGE_UP:   "\x1b[5~",
+	SCAN_PAGE_DOWN: "\x1b[6~",
+	SCAN_F1:        "\x1bOP",
+	SCAN_F2:        "\x1bOQ",
+	SCAN_F3:        "\x1bOR",
+	SCAN_F4:        "\x1bOS",
+	SCAN_F5:        "\x1b[15~",
+	SCAN_F6:        "\x1b[17~",
+	SCAN_F7:        "\x1b[18~",
+	SCAN_F8:        "\x1b[19~",
+	SCAN_F9:        "\x1b[20~",
+	SCAN_F10:       "\x1b[21~",
+	SCAN_F11:       "\x1b[23~",
+	SCAN_F12:       "\x1b[24~",
+	SCAN_ESC:       "\x1b",
+}
+
+// VT100 input sequences for EFI scan codes with Alt modifier
+var vt100AltKeys = map[uint16]string{
+	SCAN_RIGHT: "\x1b[1;3C",
+	SCAN_LEFT:  "\x1b[1;3D",
+}
+
+// ANSI to EFI color mapping
+var ansiColors = []int{
+	EFI_BLACK,
+	EFI_RED,
+	EFI_GREEN,
+	EFI_BROWN,
+	EFI_BLUE,
+	EFI_MAGENTA,
+	EFI_CYAN,
+	EFI_LIGHTGRAY,
+}
+
+// scanCode translates an EFI scan code to its VT100 input sequence.
+func scanCode(k *KeyData) []byte {
+	if k.Alt() {
+		if seq, ok := vt100AltKeys[k.Key.ScanCode]; ok {
+			return []byte(seq)
+		}
+	}
+
+	return []byte(vt100Keys[k.Key.ScanCode])
+}
+
+// SetCursorPosition calls EFI_SIMPLE_TEXT_OUTPUT_PROTOCOL.SetCursorPosition().
+func (c *Console) SetCursorPosition(col int, row int) error {
+	if c.Out == 0 {
+		return nil
+	}
+
+	status := callService(c.Out+setCursorPosition,
+		[]uint64{
+			c.Out,
+			uint64(col),
+			uint64(row),
+		},
+	)
+
+	return parseStatus(status)
+}
+
+// screen implements [vt100.Terminal] through EFI Simple Text Output Protocol
+// calls.
+type screen Console
+
+// Text outputs text at the cursor position.
+func (s *screen) Text(p []byte) error {
+	return (*Console)(s).text(p)
+}
+
+// Cursor returns the cursor position and the screen size.
+func (s *screen) Cursor() (col int, row int, cols int, rows int, err error) {
+	var mode *OutputMode
+	var w, h uint64
+
+	c := (*Console)(s)
+
+	if mode, err = c.GetMode(); err != nil {
+		return
+	}
+
+	if w, h, err = c.QueryMode(uint64(mode.Mode)); err != nil {
+		return
+	}
+
+	return int(mode.CursorColumn), int(mode.CursorRow), int(w), int(h), nil
+}
+
+// SetCursor moves the cursor to a screen position.
+func (s *screen) SetCursor(col int, row int) error {
+	return (*Console)(s).SetCursorPosition(col, row)
+}
+
+// ShowCursor shows or hides the cursor.
+func (s *screen) ShowCursor(visible bool) error {
+	return (*Console)(s).EnableCursor(visible)
+}
+
+// Erase writes n spaces from the argument position, restoring the cursor
+// position afterwards. The last screen cell is never written to avoid
+// scrolling.
+func (s *screen) Erase(col int, row int, n int) (err error) {
+	var sp []byte
+
+	c := (*Console)(s)
+	curCol, curRow, cols, rows, err := s.Cursor()
+
+	if err != nil {
+		return
+	}
+
+	if row == rows-1 {
+		n = min(n, cols-col-1)
+	}
+
+	if n <= 0 {
+		return
+	}
+
+	if err = c.SetCursorPosition(col, row); err != nil {
+		return
+	}
+
+	for i := 0; i < n; i++ {
+		sp = append(sp, space, 0x00)
+	}
+
+	if status := c.Output(sp); status != EFI_SUCCESS {
+		return parseStatus(status)
+	}
+
+	return c.SetCursorPosition(curCol, curRow)
+}
+
+// Clear clears the screen, the console has no off-screen history.
+func (s *screen) Clear(scrollback bool) error {
+	if scrollback {
+		return nil
+	}
+
+	return (*Console)(s).ClearScreen()
+}
+
+// Attribute returns the current text attribute and [DefaultAttribute].
+func (s *screen) Attribute() (current vt100.Attribute, reset vt100.Attribute, err error) {
+	var mode *OutputMode
+
+	if mode, err = (*Console)(s).GetMode(); err != nil {
+		return
+	}
+
+	return attribute(int(mode.Attribute)), attribute(DefaultAttribute), nil
+}
+
+// SetAttribute sets the text attribute, bright background colors are not
+// supported.
+func (s *screen) SetAttribute(a vt100.Attribute) error {
+	fg := ansiColors[a.Foreground&0x07] | int(a.Foreground&EFI_BRIGHT)
+	bg := ansiColors[a.Background&0x07]
+
+	return (*Console)(s).SetAttribute(uint64(fg | bg<<4))
+}
+
+// attribute converts an EFI text attribute to ANSI colors.
+func attribute(attr int) (a vt100.Attribute) {
+	for i, color := range ansiColors {
+		if attr&0x07 == color {
+			a.Foreground = uint8(i)
+		}
+
+		if (attr>>4)&0x07 == color {
+			a.Background = uint8(i)
+		}
+	}
+
+	a.Foreground |= uint8(attr & EFI_BRIGHT)
+
+	return
+}
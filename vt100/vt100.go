@@ -0,0 +1,264 @@
+// Copyright (c) The go-boot authors. All Rights Reserved.
+//
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+// Package vt100 implements interpretation of VT100 Control Sequence
+// Introducer (CSI) sequences for colors, cursor movement and erasing, on
+// consoles which lack native support for them.
+package vt100
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	esc = 0x1b
+	// Control Sequence Introducer
+	csi = '['
+	// maximum escape sequence length
+	maxSequenceSize = 32
+	// bright color variant
+	bright = 0x08
+)
+
+// Attribute represents a character rendition as ANSI color indices, the
+// bright variants have bit 3 set.
+type Attribute struct {
+	Foreground uint8
+	Background uint8
+}
+
+// Terminal represents the console operations VT100 sequences are translated
+// to.
+type Terminal interface {
+	// Text outputs text, which does not include escape sequences, at the
+	// cursor position.
+	Text(p []byte) error
+	// Cursor returns the cursor position and the screen size in
+	// characters.
+	Cursor() (col int, row int, cols int, rows int, err error)
+	// SetCursor moves the cursor to a screen position.
+	SetCursor(col int, row int) error
+	// ShowCursor shows or hides the cursor.
+	ShowCursor(visible bool) error
+	// Erase blanks n characters of a screen row, starting from the
+	// argument column, without moving the cursor.
+	Erase(col int, row int, n int) error
+	// Clear clears the screen, or only its off-screen history when
+	// scrollback is true.
+	Clear(scrollback bool) error
+	// Attribute returns the current character rendition and the one
+	// restored on reset.
+	Attribute() (current Attribute, reset Attribute, err error)
+	// SetAttribute sets the character rendition for subsequent text.
+	SetAttribute(a Attribute) error
+}
+
+// Parser interprets VT100 sequences on output written to a [Terminal],
+// sequences split across writes are buffered until complete.
+type Parser struct {
+	// incomplete escape sequence
+	seq []byte
+}
+
+// Reset discards any incomplete escape sequence.
+func (p *Parser) Reset() {
+	p.seq = nil
+}
+
+// Write outputs data to the argument terminal, interpreting VT100 sequences.
+func (p *Parser) Write(t Terminal, buf []byte) (err error) {
+	var start int
+
+	for i, b := range buf {
+		switch {
+		case len(p.seq) > 0:
+			if !p.sequence(b) {
+				continue
+			}
+
+			seq := p.seq
+			p.seq = nil
+
+			if err = escape(t, seq); err != nil {
+				return
+			}
+
+			start = i + 1
+		case b == esc:
+			if i > start {
+				if err = t.Text(buf[start:i]); err != nil {
+					return
+				}
+			}
+
+			p.seq = []byte{esc}
+		}
+	}
+
+	if len(p.seq) == 0 && start < len(buf) {
+		err = t.Text(buf[start:])
+	}
+
+	return
+}
+
+// sequence buffers escape sequence bytes, it returns whether the sequence is
+// complete.
+func (p *Parser) sequence(b byte) bool {
+	p.seq = append(p.seq, b)
+
+	switch {
+	case len(p.seq) == 2:
+		// only CSI sequences are supported, others are discarded
+		return b != csi
+	case len(p.seq) > maxSequenceSize:
+		return true
+	default:
+		// final byte
+		return len(p.seq) > 2 && b >= 0x40 && b <= 0x7e
+	}
+}
+
+// escape interprets a Control Sequence Introducer sequence.
+func escape(t Terminal, seq []byte) (err error) {
+	var params []int
+
+	if len(seq) < 3 || seq[1] != csi {
+		return
+	}
+
+	final := seq[len(seq)-1]
+	arg := string(seq[2 : len(seq)-1])
+	private := strings.HasPrefix(arg, "?")
+
+	for _, s := range strings.Split(strings.TrimPrefix(arg, "?"), ";") {
+		n, _ := strconv.Atoi(s)
+		params = append(params, n)
+	}
+
+	// n returns the i-th parameter, or def when unset
+	n := func(i int, def int) int {
+		if i >= len(params) || params[i] == 0 {
+			return def
+		}
+
+		return params[i]
+	}
+
+	switch final {
+	case 'm':
+		return sgr(t, params)
+	case 'h', 'l':
+		if private && n(0, 0) == 25 {
+			return t.ShowCursor(final == 'h')
+		}
+
+		return
+	}
+
+	col, row, cols, rows, err := t.Cursor()
+
+	if err != nil {
+		return
+	}
+
+	switch final {
+	case 'A': // CUU - Cursor Up
+		row -= n(0, 1)
+	case 'B': // CUD - Cursor Down
+		row += n(0, 1)
+	case 'C': // CUF - Cursor Forward
+		col += n(0, 1)
+	case 'D': // CUB - Cursor Back
+		col -= n(0, 1)
+	case 'E': // CNL - Cursor Next Line
+		col = 0
+		row += n(0, 1)
+	case 'F': // CPL - Cursor Previous Line
+		col = 0
+		row -= n(0, 1)
+	case 'G': // CHA - Cursor Horizontal Absolute
+		col = n(0, 1) - 1
+	case 'H', 'f': // CUP - Cursor Position
+		row = n(0, 1) - 1
+		col = n(1, 1) - 1
+	case 'J': // ED - Erase in Display
+		switch n(0, 0) {
+		case 0:
+			err = t.Erase(col, row, cols-col)
+
+			for i := row + 1; i < rows && err == nil; i++ {
+				err = t.Erase(0, i, cols)
+			}
+		case 1:
+			for i := 0; i < row && err == nil; i++ {
+				err = t.Erase(0, i, cols)
+			}
+
+			if err == nil {
+				err = t.Erase(0, row, col+1)
+			}
+		case 3:
+			err = t.Clear(true)
+		default:
+			err = t.Clear(false)
+		}
+
+		return
+	case 'K': // EL - Erase in Line
+		switch n(0, 0) {
+		case 0:
+			return t.Erase(col, row, cols-col)
+		case 1:
+			return t.Erase(0, row, col+1)
+		default:
+			return t.Erase(0, row, cols)
+		}
+	default:
+		return
+	}
+
+	col = max(0, min(col, cols-1))
+	row = max(0, min(row, rows-1))
+
+	return t.SetCursor(col, row)
+}
+
+// sgr interprets a Select Graphic Rendition sequence.
+func sgr(t Terminal, params []int) (err error) {
+	a, reset, err := t.Attribute()
+
+	if err != nil {
+		return
+	}
+
+	for _, p := range params {
+		switch {
+		case p == 0:
+			a = reset
+		case p == 1:
+			a.Foreground |= bright
+		case p == 22:
+			a.Foreground &^= bright
+		case p == 7, p == 27:
+			a.Foreground, a.Background = a.Background, a.Foreground
+		case p >= 30 && p <= 37:
+			a.Foreground = a.Foreground&bright | uint8(p-30)
+		case p == 39:
+			a.Foreground = reset.Foreground
+		case p >= 40 && p <= 47:
+			a.Background = uint8(p - 40)
+		case p == 49:
+			a.Background = reset.Background
+		case p >= 90 && p <= 97:
+			a.Foreground = uint8(p-90) | bright
+		case p >= 100 && p <= 107:
+			a.Background = uint8(p-100) | bright
+		}
+	}
+
+	return t.SetAttribute(a)
+}